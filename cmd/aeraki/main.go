@@ -50,6 +50,10 @@ func main() {
 	flag.BoolVar(&args.Master, "master", true, "Run as master")
 	flag.BoolVar(&args.EnableEnvoyFilterNSScope, "enable-envoy-filter-namespace-scope", false,
 		"Generate Envoy Filters in the service namespace")
+	flag.BoolVar(&args.EnableDubboRootNamespaceAuthz, "enable-dubbo-root-namespace-authz", false,
+		"Apply the Dubbo authorization policies in the root namespace to the Dubbo services in all namespaces")
+	flag.BoolVar(&args.EnableKafkaMesh, "enable-kafka-mesh", false,
+		"Generate the kafka_mesh filter for the Kafka services, which requires the contrib images of Envoy")
 	flag.StringVar(&args.AerakiXdsAddr, "aeraki-xds-address", constants.DefaultAerakiXdsAddr, "Aeraki xds server address")
 	flag.StringVar(&args.AerakiXdsPort, "aeraki-xds-port", constants.DefaultAerakiXdsPort, "Aeraki xds server port")
 	flag.StringVar(&args.IstiodAddr, "istiod-address", defaultIstiodAddr, "Istiod xds server address")
@@ -64,6 +68,17 @@ func main() {
 	flag.StringVar(&args.KubeDomainSuffix, "domain", defaultKubernetesDomain, "Kubernetes DNS domain suffix")
 	flag.StringVar(&args.HTTPSAddr, "httpsAddr", ":15017", "validation service HTTPS address")
 	flag.StringVar(&args.HTTPAddr, "httpAddr", ":8080", "Aeraki readiness service HTTP address")
+	flag.StringVar(&args.DubboRegistry.Type, "dubbo-registry-type", "nacos", "Dubbo registry type")
+	flag.StringVar(&args.DubboRegistry.Addr, "dubbo-registry-address", "",
+		"Dubbo registry address, the Dubbo providers are synchronized to ServiceEntries if it's set")
+	flag.StringVar(&args.DubboRegistry.NamespaceID, "dubbo-registry-namespace-id", "",
+		"Nacos namespace ID of the Dubbo registry, the default namespace is used if it's empty")
+	flag.StringVar(&args.DubboRegistry.Group, "dubbo-registry-group", "",
+		"Nacos group of the Dubbo registry, the default group is used if it's empty")
+	flag.StringVar(&args.DubboRegistry.Namespace, "dubbo-registry-namespace", "dubbo",
+		"Namespace where the ServiceEntries of the Dubbo providers are created")
+	flag.StringVar(&args.DubboRegistry.PortName, "dubbo-registry-port-name", "tcp-dubbo",
+		"Port name of the ServiceEntries of the Dubbo providers, tcp-dubbo or tcp-metaprotocol-dubbo")
 	loggingOptions := log.DefaultOptions()
 	loggingOptions.AttachFlags(flag.StringArrayVar, flag.StringVar, flag.IntVar, flag.BoolVar)
 	flag.Parse()
@@ -85,7 +100,7 @@ func main() {
 	}
 	// Create the stop channel for all of the servers.
 	stopChan := make(chan struct{}, 1)
-	args.Protocols = initGenerators()
+	args.Protocols = initGenerators(args)
 	server, err := bootstrap.NewServer(args)
 	if err != nil {
 		log.Fatalf("Failed to init Aeraki :%v", err)
@@ -142,10 +157,10 @@ func initArgsWithEnv(args *bootstrap.AerakiArgs) {
 	}
 }
 
-func initGenerators() map[protocol.Instance]envoyfilter.Generator {
+func initGenerators(args *bootstrap.AerakiArgs) map[protocol.Instance]envoyfilter.Generator {
 	return map[protocol.Instance]envoyfilter.Generator{
 		protocol.Thrift:       thrift.NewGenerator(),
-		protocol.Kafka:        kafka.NewGenerator(),
+		protocol.Kafka:        kafka.NewGenerator(args.EnableKafkaMesh),
 		protocol.Zookeeper:    zookeeper.NewGenerator(),
 		protocol.MetaProtocol: metaprotocol.NewGenerator(),
 	}
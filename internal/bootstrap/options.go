@@ -21,23 +21,36 @@ import (
 
 // AerakiArgs provides all of the configuration parameters for the Aeraki service.
 type AerakiArgs struct {
-	Master                   bool
-	IstiodAddr               string
-	AerakiXdsAddr            string
-	AerakiXdsPort            string
-	PodName                  string
-	IstioConfigMapName       string
-	HTTPSAddr                string // The listening address for HTTPS (webhooks).
-	HTTPAddr                 string // The listening address for HTTP (health).
-	RootNamespace            string
-	ClusterID                string
-	ConfigStoreSecret        string
-	ElectionID               string
-	ServerID                 string
-	LogLevel                 string
-	KubeDomainSuffix         string
-	EnableEnvoyFilterNSScope bool
-	Protocols                map[protocol.Instance]envoyfilter.Generator
+	Master                        bool
+	IstiodAddr                    string
+	AerakiXdsAddr                 string
+	AerakiXdsPort                 string
+	PodName                       string
+	IstioConfigMapName            string
+	HTTPSAddr                     string // The listening address for HTTPS (webhooks).
+	HTTPAddr                      string // The listening address for HTTP (health).
+	RootNamespace                 string
+	ClusterID                     string
+	ConfigStoreSecret             string
+	ElectionID                    string
+	ServerID                      string
+	LogLevel                      string
+	KubeDomainSuffix              string
+	EnableEnvoyFilterNSScope      bool
+	EnableDubboRootNamespaceAuthz bool
+	EnableKafkaMesh               bool
+	Protocols                     map[protocol.Instance]envoyfilter.Generator
+	DubboRegistry                 DubboRegistryArgs
+}
+
+// DubboRegistryArgs provides the configuration parameters for synchronizing a Dubbo registry to ServiceEntries.
+type DubboRegistryArgs struct {
+	Type        string // The registry type, only nacos is supported.
+	Addr        string // The registry address, synchronization is disabled if it's empty.
+	NamespaceID string // The Nacos namespace ID, the default namespace is used if it's empty.
+	Group       string // The Nacos group, the default group is used if it's empty.
+	Namespace   string // The namespace where the ServiceEntries are created.
+	PortName    string // The ServiceEntry port name, tcp-dubbo or tcp-metaprotocol-dubbo.
 }
 
 // NewAerakiArgs constructs AerakiArgs with default value.
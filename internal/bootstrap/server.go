@@ -54,6 +54,7 @@ import (
 	"github.com/aeraki-mesh/aeraki/internal/model/protocol"
 	"github.com/aeraki-mesh/aeraki/internal/plugin/dubbo"
 	"github.com/aeraki-mesh/aeraki/internal/plugin/redis"
+	"github.com/aeraki-mesh/aeraki/internal/registry"
 	"github.com/aeraki-mesh/aeraki/internal/util"
 	"github.com/aeraki-mesh/aeraki/internal/xds"
 )
@@ -111,10 +112,11 @@ func NewServer(args *AerakiArgs) (*Server, error) {
 	}
 	// configController watches Istiod through MCP over xDS to get service entry and virtual service updates
 	configController := istio.NewController(&istio.Options{
-		PodName:    args.PodName,
-		ClusterID:  args.ClusterID,
-		IstiodAddr: args.IstiodAddr,
-		NameSpace:  args.RootNamespace,
+		PodName:                       args.PodName,
+		ClusterID:                     args.ClusterID,
+		IstiodAddr:                    args.IstiodAddr,
+		NameSpace:                     args.RootNamespace,
+		EnableDubboRootNamespaceAuthz: args.EnableDubboRootNamespaceAuthz,
 	})
 	// envoyFilterController watches changes on config and create/update corresponding EnvoyFilters
 	envoyFilterController := envoyfilter.NewController(client, configController.Store, args.Protocols,
@@ -139,10 +141,16 @@ func NewServer(args *AerakiArgs) (*Server, error) {
 	routeCacheMgr.MetaRouterControllerClient = scalableCtrlMgr.GetClient()
 	// envoyFilterController uses controller manager client to get the rate limit configuration in MetaRouters
 	envoyFilterController.MetaRouterControllerClient = scalableCtrlMgr.GetClient()
-	// todo replace config with cached client
-	cfg := scalableCtrlMgr.GetConfig()
-	args.Protocols[protocol.Dubbo] = dubbo.NewGenerator(scalableCtrlMgr.GetConfig())
-	args.Protocols[protocol.Redis] = redis.New(cfg, configController.Store)
+	// the generators use controller manager client to look up Aeraki CRDs from the informer cache, while the secrets
+	// are read from the API server directly to avoid caching all the secrets in the cluster
+	authzRootNamespace := ""
+	if args.EnableDubboRootNamespaceAuthz {
+		authzRootNamespace = args.RootNamespace
+	}
+	args.Protocols[protocol.Dubbo] = dubbo.NewGenerator(scalableCtrlMgr.GetClient(), configController.Store,
+		authzRootNamespace)
+	args.Protocols[protocol.Redis] = redis.New(scalableCtrlMgr.GetClient(), scalableCtrlMgr.GetAPIReader(),
+		configController.Store)
 	// singletonCtrlMgr
 	singletonCtrlMgr, err := createSingletonControllers(args, kubeConfig)
 	if err != nil {
@@ -290,6 +298,13 @@ func createScalableControllers(args *AerakiArgs, kubeConfig *rest.Config,
 	if err := aerakischeme.AddToScheme(mgr.GetScheme()); err != nil {
 		return nil, err
 	}
+	if err := kube.AddHostIndexes(mgr); err != nil {
+		return nil, err
+	}
+	// the passwords of redis auth are inlined in the EnvoyFilters, so they need to be updated when the secrets change
+	if err := kube.AddSecretController(mgr, updateEnvoyFilter); err != nil {
+		return nil, err
+	}
 	return mgr, nil
 }
 
@@ -317,9 +332,31 @@ func createSingletonControllers(args *AerakiArgs, kubeConfig *rest.Config) (mana
 	if err != nil {
 		aerakiLog.Fatalf("could not add schema: %e", err)
 	}
+	if err := addDubboRegistrySyncer(args, mgr); err != nil {
+		return nil, err
+	}
 	return mgr, nil
 }
 
+// addDubboRegistrySyncer adds a syncer to synchronize the Dubbo registry to ServiceEntries, it's run only by the
+// leader of the singleton controllers
+func addDubboRegistrySyncer(args *AerakiArgs, mgr manager.Manager) error {
+	registryArgs := args.DubboRegistry
+	if registryArgs.Addr == "" {
+		return nil
+	}
+	var dubboRegistry registry.Registry
+	switch registryArgs.Type {
+	case "nacos":
+		dubboRegistry = registry.NewNacosRegistry(registryArgs.Type, registryArgs.Addr, registryArgs.NamespaceID,
+			registryArgs.Group)
+	default:
+		return fmt.Errorf("unsupported dubbo registry type: %s", registryArgs.Type)
+	}
+	return mgr.Add(registry.NewSyncer(dubboRegistry, mgr.GetClient(), registryArgs.Namespace,
+		registryArgs.PortName))
+}
+
 // Start starts all components of the Aeraki service. Serving can be canceled at any time by closing the provided stop
 // channel.
 // This method won't block
@@ -21,4 +21,6 @@ const (
 	DefaultAerakiXdsPort = ":15010"
 	// DefaultAerakiXdsAddr is the default value for Aeraki xds address
 	DefaultAerakiXdsAddr = "aeraki.istio-system"
+	// HostIndex is the cache field index of the hosts of MetaRouters, RedisServices and RedisDestinations
+	HostIndex = "spec.host"
 )
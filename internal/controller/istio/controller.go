@@ -52,7 +52,8 @@ var (
 				MustAdd(collections.VirtualService).
 				MustAdd(collections.DestinationRule).
 				MustAdd(collections.EnvoyFilter).
-				MustAdd(collections.Gateway).Build()
+				MustAdd(collections.Gateway).
+				MustAdd(collections.AuthorizationPolicy).Build()
 )
 
 // Options for config controller
@@ -61,6 +62,9 @@ type Options struct {
 	ClusterID  string
 	NameSpace  string
 	IstiodAddr string
+	// EnableDubboRootNamespaceAuthz applies the authorization policies in the root namespace to the dubbo services in
+	// all namespaces
+	EnableDubboRootNamespaceAuthz bool
 }
 
 // Controller watches Istio config xDS server and notifies the listeners when config changes.
@@ -173,7 +177,9 @@ func (c *Controller) RegisterEventHandler(handler func(*istioconfig.Config, *ist
 		// * ServiceEntry: Services handled by Aeraki: tcp-metaprotocol, tcp-dubbo, tcp-thrift, tcp-redis
 		// * VirtualService: Route rules for dubbo and thrift
 		// * DestinationRule: the Load balancing policy in set in the dr,
-		//   httpHeaderName is used to convey the metadata key for generating hash
+		//   httpHeaderName is used to convey the metadata key for generating hash,
+		//   and the tls settings are used to generate the upstream TLS of redis clusters
+		// * AuthorizationPolicy: translated to the RBAC filters of dubbo services
 		switch curr.GroupVersionKind {
 		case gvk.ServiceEntry:
 			controllerLog.Infof("service entry changed: %s %s", event.String(), curr.Name)
@@ -195,6 +201,11 @@ func (c *Controller) RegisterEventHandler(handler func(*istioconfig.Config, *ist
 			if c.shouldHandleGatewayChange(&prev, &curr) {
 				handler(&prev, &curr, event)
 			}
+		case gvk.AuthorizationPolicy:
+			controllerLog.Infof("authorization policy changed: %s %s", event.String(), curr.Name)
+			if c.shouldHandleAuthorizationPolicy(&curr) {
+				handler(&prev, &curr, event)
+			}
 		}
 	}
 
@@ -303,6 +314,30 @@ func (c *Controller) shouldHandleDestinationRule(drConfig *istioconfig.Config) b
 	return false
 }
 
+func (c *Controller) shouldHandleAuthorizationPolicy(apConfig *istioconfig.Config) bool {
+	// An authorization policy may be applied to the dubbo services in the same namespace, and the ones in the root
+	// namespace apply to the dubbo services in all namespaces if it's enabled
+	namespace := apConfig.Namespace
+	if c.options.EnableDubboRootNamespaceAuthz && namespace == c.options.NameSpace {
+		namespace = ""
+	}
+	serviceEntries := c.Store.List(
+		collections.ServiceEntry.GroupVersionKind(), namespace)
+	for i := range serviceEntries {
+		service, ok := serviceEntries[i].Spec.(*networking.ServiceEntry)
+		if !ok { // should never happen
+			controllerLog.Errorf("failed in getting a service entry: %s", serviceEntries[i].Name)
+			return false
+		}
+		for _, port := range service.Ports {
+			if protocol.GetLayer7ProtocolFromPortName(port.Name) == protocol.Dubbo {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (c *Controller) shouldHandleGateway(gwConfig *istioconfig.Config) bool {
 	gw, ok := gwConfig.Spec.(*networking.Gateway)
 	if !ok {
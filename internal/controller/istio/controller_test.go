@@ -0,0 +1,63 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istio
+
+import (
+	"testing"
+
+	networking "istio.io/api/networking/v1alpha3"
+	istioconfig "istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+)
+
+func TestShouldHandleAuthorizationPolicy(t *testing.T) {
+	const rootNamespace = "istio-system"
+	dubboService := istioconfig.Config{
+		Meta: istioconfig.Meta{GroupVersionKind: gvk.ServiceEntry, Name: "dubbo", Namespace: "dubbo"},
+		Spec: &networking.ServiceEntry{
+			Hosts: []string{"org.apache.dubbo.samples.basic.api.demoservice"},
+			Ports: []*networking.ServicePort{{Number: 20880, Name: "tcp-dubbo"}},
+		},
+	}
+	policy := func(namespace string) *istioconfig.Config {
+		return &istioconfig.Config{
+			Meta: istioconfig.Meta{GroupVersionKind: gvk.AuthorizationPolicy, Name: "policy", Namespace: namespace},
+		}
+	}
+
+	cases := []struct {
+		name          string
+		namespace     string
+		rootNamespace bool
+		want          bool
+	}{
+		{name: "policy of the dubbo namespace", namespace: "dubbo", want: true},
+		{name: "policy of another namespace", namespace: "default"},
+		{name: "policy of the root namespace", namespace: rootNamespace},
+		{name: "policy of the root namespace enabled", namespace: rootNamespace, rootNamespace: true, want: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			controller := NewController(&Options{NameSpace: rootNamespace, EnableDubboRootNamespaceAuthz: c.rootNamespace})
+			if _, err := controller.Store.Create(dubboService); err != nil {
+				t.Fatal(err)
+			}
+			if got := controller.shouldHandleAuthorizationPolicy(policy(c.namespace)); got != c.want {
+				t.Errorf("want %v, got %v", c.want, got)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ package kube
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/aeraki-mesh/client-go/pkg/apis/dubbo/v1alpha1"
 	"istio.io/pkg/log"
@@ -81,8 +82,10 @@ var (
 				if !ok {
 					return false
 				}
+				// the workloadSelector and dry-run annotations change how a policy is applied
 				if old.GetDeletionTimestamp() != newDA.GetDeletionTimestamp() ||
-					old.GetGeneration() != newDA.GetGeneration() {
+					old.GetGeneration() != newDA.GetGeneration() ||
+					!reflect.DeepEqual(old.GetAnnotations(), newDA.GetAnnotations()) {
 					return true
 				}
 			default:
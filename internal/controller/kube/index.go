@@ -0,0 +1,48 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+
+	metaprotocol "github.com/aeraki-mesh/client-go/pkg/apis/metaprotocol/v1alpha1"
+	redis "github.com/aeraki-mesh/client-go/pkg/apis/redis/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/aeraki-mesh/aeraki/internal/config/constants"
+)
+
+// AddHostIndexes adds the host field indexes to the cache of the manager, so the MetaRouters, RedisServices and
+// RedisDestinations of a host can be looked up with client.MatchingFields{constants.HostIndex: host}
+func AddHostIndexes(mgr manager.Manager) error {
+	indexer := mgr.GetFieldIndexer()
+	if err := indexer.IndexField(context.TODO(), &metaprotocol.MetaRouter{}, constants.HostIndex,
+		func(obj client.Object) []string {
+			return obj.(*metaprotocol.MetaRouter).Spec.Hosts
+		}); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(context.TODO(), &redis.RedisService{}, constants.HostIndex,
+		func(obj client.Object) []string {
+			return obj.(*redis.RedisService).Spec.Host
+		}); err != nil {
+		return err
+	}
+	return indexer.IndexField(context.TODO(), &redis.RedisDestination{}, constants.HostIndex,
+		func(obj client.Object) []string {
+			return []string{obj.(*redis.RedisDestination).Spec.Host}
+		})
+}
@@ -16,6 +16,7 @@ package kube
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/aeraki-mesh/client-go/pkg/apis/redis/v1alpha1"
 	"istio.io/pkg/log"
@@ -96,8 +97,10 @@ var (
 				if !ok {
 					return false
 				}
+				// the additional downstream auth secrets are specified in the annotations
 				if old.GetDeletionTimestamp() != newRS.GetDeletionTimestamp() ||
-					old.GetGeneration() != newRS.GetGeneration() {
+					old.GetGeneration() != newRS.GetGeneration() ||
+					!reflect.DeepEqual(old.GetAnnotations(), newRS.GetAnnotations()) {
 					return true
 				}
 			case *v1alpha1.RedisDestination:
@@ -105,8 +108,10 @@ var (
 				if !ok {
 					return false
 				}
+				// the refresh settings of redis clusters are specified in the annotations
 				if old.GetDeletionTimestamp() != newRD.GetDeletionTimestamp() ||
-					old.GetGeneration() != newRD.GetGeneration() {
+					old.GetGeneration() != newRD.GetGeneration() ||
+					!reflect.DeepEqual(old.GetAnnotations(), newRD.GetAnnotations()) {
 					return true
 				}
 			default:
@@ -0,0 +1,141 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"time"
+
+	redisapi "github.com/aeraki-mesh/api/redis/v1alpha1"
+	"github.com/aeraki-mesh/client-go/pkg/apis/redis/v1alpha1"
+	"istio.io/pkg/log"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/aeraki-mesh/aeraki/internal/model"
+)
+
+var secretLog = log.RegisterScope("secret-controller", "secret-controller debugging", 0)
+
+// defaultSecretPollInterval is the interval to check the secrets referenced by redis auth
+const defaultSecretPollInterval = 30 * time.Second
+
+// SecretController triggers a push when a secret referenced by the auth of a RedisService or RedisDestination changes,
+// so the rotated passwords are applied to the EnvoyFilters.
+// The referenced secrets are polled through the API reader instead of being watched, so Aeraki only needs the
+// permission to get secrets, and the other secrets in the cluster are never read.
+type SecretController struct {
+	// client reads the RedisServices and RedisDestinations from the informer cache
+	client client.Client
+	// reader reads the secrets from the API server
+	reader      client.Reader
+	triggerPush func() error
+	interval    time.Duration
+	// versions are the resource versions of the referenced secrets in the last check, the version of a missing
+	// secret is empty
+	versions map[types.NamespacedName]string
+}
+
+// Start checks the referenced secrets periodically until the context is done, it implements manager.Runnable
+func (r *SecretController) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.check(ctx); err != nil {
+				secretLog.Errorf("failed to check the secrets of redis auth: %v", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// check triggers a push if any referenced secret has changed since the last check. The secrets seen for the first
+// time don't trigger a push, since the EnvoyFilters have been generated with them when they got referenced.
+func (r *SecretController) check(ctx context.Context) error {
+	secrets, err := r.referencedSecrets(ctx)
+	if err != nil {
+		return err
+	}
+	versions := make(map[types.NamespacedName]string, len(secrets))
+	changed := false
+	for key := range secrets {
+		secret := &corev1.Secret{}
+		if err := r.reader.Get(ctx, key, secret); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		versions[key] = secret.ResourceVersion
+		if version, ok := r.versions[key]; ok && version != secret.ResourceVersion {
+			secretLog.Infof("secret %s changed", key)
+			changed = true
+		}
+	}
+	r.versions = versions
+	if changed && r.triggerPush != nil {
+		return r.triggerPush()
+	}
+	return nil
+}
+
+func (r *SecretController) referencedSecrets(ctx context.Context) (map[types.NamespacedName]struct{}, error) {
+	secrets := make(map[types.NamespacedName]struct{})
+	redisServices := &v1alpha1.RedisServiceList{}
+	if err := r.client.List(ctx, redisServices); err != nil {
+		return nil, err
+	}
+	for _, rs := range redisServices.Items {
+		names := append(secretNames(rs.Spec.GetSettings().GetAuth()), model.GetRedisDownstreamAuthSecrets(rs.Annotations)...)
+		for _, name := range names {
+			secrets[types.NamespacedName{Namespace: rs.Namespace, Name: name}] = struct{}{}
+		}
+	}
+	redisDestinations := &v1alpha1.RedisDestinationList{}
+	if err := r.client.List(ctx, redisDestinations); err != nil {
+		return nil, err
+	}
+	for _, rd := range redisDestinations.Items {
+		for _, name := range secretNames(rd.Spec.GetTrafficPolicy().GetConnectionPool().GetRedis().GetAuth()) {
+			secrets[types.NamespacedName{Namespace: rd.Namespace, Name: name}] = struct{}{}
+		}
+	}
+	return secrets, nil
+}
+
+func secretNames(auth *redisapi.Auth) []string {
+	if secret := auth.GetSecret(); secret != nil && secret.Name != "" {
+		return []string{secret.Name}
+	}
+	return nil
+}
+
+// AddSecretController adds SecretController
+func AddSecretController(mgr manager.Manager, triggerPush func() error) error {
+	err := mgr.Add(&SecretController{
+		client:      mgr.GetClient(),
+		reader:      mgr.GetAPIReader(),
+		triggerPush: triggerPush,
+		interval:    defaultSecretPollInterval,
+	})
+	if err != nil {
+		return err
+	}
+	controllerLog.Infof("SecretController registered")
+	return nil
+}
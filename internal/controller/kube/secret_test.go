@@ -0,0 +1,103 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"testing"
+
+	spec "github.com/aeraki-mesh/api/redis/v1alpha1"
+	"github.com/aeraki-mesh/client-go/pkg/apis/redis/v1alpha1"
+	aerakischeme "github.com/aeraki-mesh/client-go/pkg/clientset/versioned/scheme"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSecretController(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := aerakischeme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	referenced := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis-auth", Namespace: "redis"},
+		Data:       map[string][]byte{"password": []byte("v1")},
+	}
+	unrelated := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "redis"},
+		Data:       map[string][]byte{"password": []byte("v1")},
+	}
+	destination := &v1alpha1.RedisDestination{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "redis"},
+		Spec: spec.RedisDestination{
+			Host: "redis.redis.svc.cluster.local",
+			TrafficPolicy: &spec.TrafficPolicy{
+				ConnectionPool: &spec.ConnectionPoolSettings{
+					Redis: &spec.RedisSettings{
+						Auth: &spec.Auth{
+							Auth: &spec.Auth_Secret{Secret: &spec.SecretReference{Name: "redis-auth"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(referenced, unrelated, destination).Build()
+
+	pushes := 0
+	controller := &SecretController{
+		client: c,
+		reader: c,
+		triggerPush: func() error {
+			pushes++
+			return nil
+		},
+	}
+	ctx := context.TODO()
+	check := func(step string, wantPushes int) {
+		t.Helper()
+		if err := controller.check(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if pushes != wantPushes {
+			t.Errorf("%s: want %d pushes, got %d", step, wantPushes, pushes)
+		}
+	}
+
+	check("first check", 0)
+	check("nothing changed", 0)
+
+	unrelated.Data["password"] = []byte("v2")
+	if err := c.Update(ctx, unrelated); err != nil {
+		t.Fatal(err)
+	}
+	check("unrelated secret changed", 0)
+
+	referenced.Data["password"] = []byte("v2")
+	if err := c.Update(ctx, referenced); err != nil {
+		t.Fatal(err)
+	}
+	check("referenced secret changed", 1)
+
+	if err := c.Delete(ctx, referenced); err != nil {
+		t.Fatal(err)
+	}
+	check("referenced secret deleted", 2)
+}
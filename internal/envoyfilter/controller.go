@@ -126,7 +126,7 @@ func (c *Controller) mainLoop(stop <-chan struct{}) {
 }
 
 func (c *Controller) pushEnvoyFilters2APIServer() error {
-	generatedEnvoyFilters, err := c.generateEnvoyFilters()
+	generatedEnvoyFilters, failedHosts, err := c.generateEnvoyFilters()
 	controllerLog.Debugf("create envoyfilter: %v", len(generatedEnvoyFilters))
 	if err != nil {
 		return fmt.Errorf("failed to generate EnvoyFilter: %v", err)
@@ -140,6 +140,12 @@ func (c *Controller) pushEnvoyFilters2APIServer() error {
 	for i := range existingEnvoyFilters.Items {
 		oldEnvoyFilter := existingEnvoyFilters.Items[i]
 		if _, ok := generatedEnvoyFilters[envoyFilterMapKey(oldEnvoyFilter.Name, oldEnvoyFilter.Namespace)]; !ok {
+			// the current EnvoyFilters of a service are kept if its new EnvoyFilters can't be generated
+			if isEnvoyFilterOfFailedHost(oldEnvoyFilter.Name, failedHosts) {
+				controllerLog.Warnf("keeping EnvoyFilter: namespace: %s name: %s", oldEnvoyFilter.Namespace,
+					oldEnvoyFilter.Name)
+				continue
+			}
 			controllerLog.Infof("deleting EnvoyFilter: namespace: %s name: %s %v", oldEnvoyFilter.Namespace,
 				oldEnvoyFilter.Name, model.Struct2JSON(oldEnvoyFilter))
 			err = c.istioClientset.NetworkingV1alpha3().EnvoyFilters(oldEnvoyFilter.Namespace).Delete(context.TODO(),
@@ -179,6 +185,15 @@ func (c *Controller) pushEnvoyFilters2APIServer() error {
 	return err
 }
 
+func isEnvoyFilterOfFailedHost(name string, failedHosts map[string]bool) bool {
+	for host := range failedHosts {
+		if isEnvoyFilterOfHost(name, host) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Controller) toEnvoyFilterCRD(newEf *model.EnvoyFilterWrapper,
 	oldEf *v1alpha3.EnvoyFilter) *v1alpha3.EnvoyFilter {
 	envoyFilter := &v1alpha3.EnvoyFilter{
@@ -197,20 +212,24 @@ func (c *Controller) toEnvoyFilterCRD(newEf *model.EnvoyFilterWrapper,
 	return envoyFilter
 }
 
-func (c *Controller) generateEnvoyFilters() (map[string]*model.EnvoyFilterWrapper, error) {
+// generateEnvoyFilters generates the EnvoyFilters of all the services, the hosts of the services whose EnvoyFilters
+// can't be generated are also returned
+func (c *Controller) generateEnvoyFilters() (map[string]*model.EnvoyFilterWrapper, map[string]bool, error) {
 	envoyFilters := make(map[string]*model.EnvoyFilterWrapper)
+	failedHosts := make(map[string]bool)
 	serviceEntries := c.configStore.List(gvk.ServiceEntry, "")
 
 	for i := range serviceEntries {
 		service, ok := serviceEntries[i].Spec.(*networking.ServiceEntry)
 		if !ok { // should never happen
-			return envoyFilters, fmt.Errorf("failed in getting a service entry: %s", serviceEntries[i].Labels)
+			return envoyFilters, failedHosts, fmt.Errorf("failed in getting a service entry: %s",
+				serviceEntries[i].Labels)
 		}
 
 		if len(service.Hosts) == 0 {
 			controllerLog.Errorf("host should not be empty: %s", serviceEntries[i].Name)
 			// We can't retry in this scenario
-			return envoyFilters, nil
+			return envoyFilters, failedHosts, nil
 		}
 
 		if len(service.Hosts) > 1 {
@@ -225,10 +244,10 @@ func (c *Controller) generateEnvoyFilters() (map[string]*model.EnvoyFilterWrappe
 
 				ctx, err := c.envoyFilterContext(service, &serviceEntries[i])
 				if err != nil {
-					return envoyFilters, err
+					return envoyFilters, failedHosts, err
 				}
 				if ctx == nil {
-					return envoyFilters, err
+					return envoyFilters, failedHosts, err
 				}
 				envoyFilterWrappers, err := generator.Generate(ctx)
 				if err == nil {
@@ -239,6 +258,7 @@ func (c *Controller) generateEnvoyFilters() (map[string]*model.EnvoyFilterWrappe
 					controllerLog.Errorf("failed to generate envoy filter: service: %s, port: %s, error: %v",
 						serviceEntries[i].Name,
 						port.Name, err)
+					failedHosts[service.Hosts[0]] = true
 				}
 				break
 			}
@@ -248,7 +268,7 @@ func (c *Controller) generateEnvoyFilters() (map[string]*model.EnvoyFilterWrappe
 	// generate envoyFilters for gateway with tcp-metaprotocol server
 	err := c.generateGatewayEnvoyFilters(envoyFilters)
 
-	return envoyFilters, err
+	return envoyFilters, failedHosts, err
 }
 
 func (c *Controller) generateGatewayEnvoyFilters(envoyFilters map[string]*model.EnvoyFilterWrapper) error {
@@ -341,7 +361,7 @@ func (c *Controller) createEnvoyFiltersOnExportNSs(ctx *model.EnvoyFilterContext
 // envoyFilterContext wraps all the resources needed to create the EnvoyFilter
 func (c *Controller) envoyFilterContext(service *networking.ServiceEntry,
 	serviceEntry *config.Config) (*model.EnvoyFilterContext, error) {
-	relatedVs, err := c.findRelatedVirtualService(service)
+	relatedVs, err := model.FindRelatedVirtualService(c.configStore, service)
 	if err != nil {
 		return nil, fmt.Errorf("failed in finding the related virtual service : %s: %v", service.Hosts[0], err)
 	}
@@ -469,31 +489,10 @@ func envoyFilterMapKey(name, ns string) string {
 	return ns + "-" + name
 }
 
-func (c *Controller) findRelatedVirtualService(service *networking.ServiceEntry) (*model.VirtualServiceWrapper, error) {
-	virtualServices := c.configStore.List(gvk.VirtualService, "")
-
-	for i := range virtualServices {
-		vs, ok := virtualServices[i].Spec.(*networking.VirtualService)
-		if !ok { // should never happen
-			return nil, fmt.Errorf("failed in getting a virtual service: %s", virtualServices[i].Name)
-		}
-
-		//Todo: we may need to deal with delegate Virtual services
-		for _, host := range vs.Hosts {
-			if host == service.Hosts[0] {
-				return &model.VirtualServiceWrapper{
-					Meta: virtualServices[i].Meta,
-					Spec: vs,
-				}, nil
-			}
-		}
-	}
-	return nil, nil
-}
-
 func (c *Controller) findRelatedMetaRouter(service *networking.ServiceEntry) (*metaprotocol.MetaRouter, error) {
 	metaRouterList := &metaprotocol.MetaRouterList{}
-	err := c.MetaRouterControllerClient.List(context.TODO(), metaRouterList, &client.ListOptions{})
+	err := c.MetaRouterControllerClient.List(context.TODO(), metaRouterList,
+		client.MatchingFields{constants.HostIndex: service.Hosts[0]})
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +31,9 @@ import (
 
 var generatorLog = log.RegisterScope("aeraki-generator", "aeraki generator", 0)
 
+// WorkloadSelectorAnnotation selects the workloads which a ServiceEntry or a DubboAuthorizationPolicy applies to
+const WorkloadSelectorAnnotation = "workloadSelector"
+
 // GenerateInsertBeforeNetworkFilter generates an EnvoyFilter that inserts a protocol specified filter before the tcp
 // proxy
 func GenerateInsertBeforeNetworkFilter(service *model.ServiceEntryWrapper, outboundProxy proto.Message,
@@ -169,6 +172,11 @@ func hasInboundWorkloadSelector(selector *networking.WorkloadSelector) bool {
 	return len(selector.Labels) != 0
 }
 
+// InboundWorkloadLabels returns the labels of the workloads which the inbound EnvoyFilter of a service applies to
+func InboundWorkloadLabels(service *model.ServiceEntryWrapper) map[string]string {
+	return inboundEnvoyFilterWorkloadSelector(service).Labels
+}
+
 func inboundEnvoyFilterWorkloadSelector(service *model.ServiceEntryWrapper) *networking.WorkloadSelector {
 	selector := service.Spec.WorkloadSelector
 	if selector == nil || selector.Labels == nil {
@@ -176,20 +184,35 @@ func inboundEnvoyFilterWorkloadSelector(service *model.ServiceEntryWrapper) *net
 			Labels: make(map[string]string),
 		}
 	}
-	if len(selector.Labels) == 0 {
-		label := strings.ReplaceAll(service.Annotations["workloadSelector"], " ", "")
-		labelSlice := strings.Split(label, ":")
-		if len(labelSlice) == 1 {
-			selector.Labels["app"] = label
-		} else if len(labelSlice) == 2 {
-			selector.Labels[labelSlice[0]] = labelSlice[1]
-		} else {
-			log.Errorf("not support workloadselector")
+	if _, exist := service.Annotations[WorkloadSelectorAnnotation]; exist && len(selector.Labels) == 0 {
+		labels, err := ParseWorkloadSelectorAnnotation(service.Annotations)
+		if err != nil {
+			log.Errorf("invalid workloadSelector annotation of service %s/%s: %v", service.Namespace,
+				service.Name, err)
+		}
+		for k, v := range labels {
+			selector.Labels[k] = v
 		}
 	}
 	return selector
 }
 
+// ParseWorkloadSelectorAnnotation parses the workloadSelector annotation, which is in the format of "key:value",
+// or "value" as a shorthand of "app:value".
+// An error is returned if the annotation is empty or malformed.
+func ParseWorkloadSelectorAnnotation(annotations map[string]string) (map[string]string, error) {
+	label := strings.ReplaceAll(annotations[WorkloadSelectorAnnotation], " ", "")
+	labelSlice := strings.Split(label, ":")
+	switch {
+	case len(labelSlice) == 1 && label != "":
+		return map[string]string{"app": label}, nil
+	case len(labelSlice) == 2 && labelSlice[0] != "" && labelSlice[1] != "":
+		return map[string]string{labelSlice[0]: labelSlice[1]}, nil
+	default:
+		return nil, fmt.Errorf("unsupported workloadSelector: %q", annotations[WorkloadSelectorAnnotation])
+	}
+}
+
 func outboundEnvoyFilterName(host, vip string, port int) string {
 	return fmt.Sprintf("aeraki-outbound-%s-%s-%d", host, vip, port)
 }
@@ -198,6 +221,31 @@ func inboundEnvoyFilterName(host string, port int) string {
 	return fmt.Sprintf("aeraki-inbound-%s-%d", host, port)
 }
 
+// isEnvoyFilterOfHost returns true if the EnvoyFilter name is generated for a service host by
+// outboundEnvoyFilterName or inboundEnvoyFilterName
+func isEnvoyFilterOfHost(name, host string) bool {
+	if rest, ok := strings.CutPrefix(name, "aeraki-outbound-"+host+"-"); ok {
+		vip, port, found := strings.Cut(rest, "-")
+		return found && vip != "" && isDigits(port)
+	}
+	if port, ok := strings.CutPrefix(name, "aeraki-inbound-"+host+"-"); ok {
+		return isDigits(port)
+	}
+	return false
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 func generateValue(proxy proto.Message, filterName, filterType string) (*_struct.Struct, error) {
 	var buf []byte
 	var err error
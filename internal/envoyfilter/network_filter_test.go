@@ -88,3 +88,71 @@ func Test_inboudEnvoyFilterWorkloadSelector(t *testing.T) {
 		})
 	}
 }
+
+func TestParseWorkloadSelectorAnnotation(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       map[string]string
+		wantErr    bool
+	}{
+		{
+			name:       "app shorthand",
+			annotation: "dubbo-sample-provider",
+			want:       map[string]string{"app": "dubbo-sample-provider"},
+		},
+		{
+			name:       "key and value",
+			annotation: "version: v1",
+			want:       map[string]string{"version": "v1"},
+		},
+		{
+			name:       "empty",
+			annotation: " ",
+			wantErr:    true,
+		},
+		{
+			name:       "empty value",
+			annotation: "version:",
+			wantErr:    true,
+		},
+		{
+			name:       "malformed",
+			annotation: "app:dubbo:v1",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWorkloadSelectorAnnotation(map[string]string{WorkloadSelectorAnnotation: tt.annotation})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWorkloadSelectorAnnotation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseWorkloadSelectorAnnotation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsEnvoyFilterOfHost(t *testing.T) {
+	const host = "redis.redis.svc.cluster.local"
+	cases := []struct {
+		name string
+		want bool
+	}{
+		{name: outboundEnvoyFilterName(host, "10.0.0.1", 6379), want: true},
+		{name: inboundEnvoyFilterName(host, 6379), want: true},
+		{name: outboundEnvoyFilterName("redis.redis.svc.cluster.local-canary", "10.0.0.1", 6379)},
+		{name: inboundEnvoyFilterName("redis.redis.svc.cluster.local-canary", 6379)},
+		{name: outboundEnvoyFilterName("cache.redis.svc.cluster.local", "10.0.0.1", 6379)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := isEnvoyFilterOfHost(c.name, host); got != c.want {
+				t.Errorf("want %v, got %v", c.want, got)
+			}
+		})
+	}
+}
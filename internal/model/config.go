@@ -15,10 +15,14 @@
 package model
 
 import (
+	"fmt"
+
 	metaprotocol "github.com/aeraki-mesh/client-go/pkg/apis/metaprotocol/v1alpha1"
 	networking "istio.io/api/networking/v1alpha3"
+	istiomodel "istio.io/istio/pilot/pkg/model"
 	istioconfig "istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/schema/gvk"
 )
 
 // ServiceEntryWrapper wraps an Istio ServiceEntry and its metadata, including name, annotations and labels.
@@ -41,6 +45,31 @@ type VirtualServiceWrapper struct {
 	Spec *networking.VirtualService
 }
 
+// FindRelatedVirtualService returns the VirtualService of a service, nil is returned if the service has no
+// VirtualService
+func FindRelatedVirtualService(store istiomodel.ConfigStore,
+	service *networking.ServiceEntry) (*VirtualServiceWrapper, error) {
+	virtualServices := store.List(gvk.VirtualService, "")
+
+	for i := range virtualServices {
+		vs, ok := virtualServices[i].Spec.(*networking.VirtualService)
+		if !ok { // should never happen
+			return nil, fmt.Errorf("failed in getting a virtual service: %s", virtualServices[i].Name)
+		}
+
+		//Todo: we may need to deal with delegate Virtual services
+		for _, host := range vs.Hosts {
+			if host == service.Hosts[0] {
+				return &VirtualServiceWrapper{
+					Meta: virtualServices[i].Meta,
+					Spec: vs,
+				}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
 // DestinationRuleWrapper wraps an Istio DestinationRule and its metadata, including name, annotations and labels.
 type DestinationRuleWrapper struct {
 	istioconfig.Meta
@@ -0,0 +1,38 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DubboSerializationAnnotation is the ServiceEntry annotation to declare the serialization of a Dubbo service
+	DubboSerializationAnnotation = "dubbo.aeraki.io/serialization"
+	// DubboSerializationHessian2 is the default serialization, and the only one supported by Envoy's dubbo_proxy
+	DubboSerializationHessian2 = "hessian2"
+)
+
+// ValidateDubboSerialization returns an error if the serialization declared in the annotations of a Dubbo service is
+// not hessian2, which is the only serialization supported by Envoy's dubbo_proxy and the MetaProtocol dubbo codec
+func ValidateDubboSerialization(annotations map[string]string) error {
+	serialization := strings.ToLower(strings.TrimSpace(annotations[DubboSerializationAnnotation]))
+	if serialization != "" && serialization != DubboSerializationHessian2 {
+		return fmt.Errorf("unsupported dubbo serialization: %s, only %s is supported", serialization,
+			DubboSerializationHessian2)
+	}
+	return nil
+}
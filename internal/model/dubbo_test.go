@@ -0,0 +1,43 @@
+// Copyright 2020 Envoyproxy Authors
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import "testing"
+
+func TestValidateDubboSerialization(t *testing.T) {
+	cases := []struct {
+		name          string
+		serialization string
+		wantErr       bool
+	}{
+		{name: "default"},
+		{name: "hessian2", serialization: " Hessian2 "},
+		// the MetaProtocol dubbo codec decodes the body with hessian2 as well, so there's no fallback for protobuf
+		{name: "protobuf", serialization: "protobuf", wantErr: true},
+		{name: "unknown", serialization: "foo", wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			annotations := map[string]string{}
+			if c.serialization != "" {
+				annotations[DubboSerializationAnnotation] = c.serialization
+			}
+			if err := ValidateDubboSerialization(annotations); (err != nil) != c.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
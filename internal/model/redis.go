@@ -0,0 +1,57 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// RedisDownstreamAuthSecretsAnnotation is the annotation of a RedisService which lists the secrets of the additional
+// passwords accepted from the downstream clients, separated by commas
+const RedisDownstreamAuthSecretsAnnotation = "redis.aeraki.io/downstreamAuthSecrets"
+
+// GetRedisDownstreamAuthSecrets returns the names of the secrets in the downstream auth secrets annotation of a
+// RedisService
+func GetRedisDownstreamAuthSecrets(annotations map[string]string) []string {
+	var names []string
+	for _, name := range strings.Split(annotations[RedisDownstreamAuthSecretsAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ValidateRedisDownstreamAuthSecrets returns an error if the downstream auth secrets annotation of a RedisService is
+// present but doesn't list valid secret names
+func ValidateRedisDownstreamAuthSecrets(annotations map[string]string) error {
+	if _, ok := annotations[RedisDownstreamAuthSecretsAnnotation]; !ok {
+		return nil
+	}
+	names := GetRedisDownstreamAuthSecrets(annotations)
+	if len(names) == 0 {
+		return fmt.Errorf("no secret in %s annotation", RedisDownstreamAuthSecretsAnnotation)
+	}
+	for _, name := range names {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("invalid secret name %q in %s annotation: %s", name,
+				RedisDownstreamAuthSecretsAnnotation, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 Envoyproxy Authors
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import "testing"
+
+func TestValidateRedisDownstreamAuthSecrets(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		wantErr     bool
+	}{
+		{name: "no annotation"},
+		{
+			name:        "secrets",
+			annotations: map[string]string{RedisDownstreamAuthSecretsAnnotation: "team-a, team-b"},
+		},
+		{
+			name:        "empty",
+			annotations: map[string]string{RedisDownstreamAuthSecretsAnnotation: " , "},
+			wantErr:     true,
+		},
+		{
+			name:        "invalid secret name",
+			annotations: map[string]string{RedisDownstreamAuthSecretsAnnotation: "team-a,Team_B"},
+			wantErr:     true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := ValidateRedisDownstreamAuthSecrets(c.annotations); (err != nil) != c.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
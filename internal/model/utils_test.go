@@ -0,0 +1,66 @@
+// Copyright 2020 Envoyproxy Authors
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+func consistentHash(header string) *networking.TrafficPolicy {
+	return &networking.TrafficPolicy{
+		LoadBalancer: &networking.LoadBalancerSettings{
+			LbPolicy: &networking.LoadBalancerSettings_ConsistentHash{
+				ConsistentHash: &networking.LoadBalancerSettings_ConsistentHashLB{
+					HashKey: &networking.LoadBalancerSettings_ConsistentHashLB_HttpHeaderName{
+						HttpHeaderName: header,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGetHashPolicy(t *testing.T) {
+	dr := &DestinationRuleWrapper{Spec: &networking.DestinationRule{
+		TrafficPolicy: consistentHash("x-tenant"),
+		Subsets: []*networking.Subset{
+			{Name: "v1", TrafficPolicy: consistentHash("x-user")},
+			{Name: "v2"},
+		},
+	}}
+
+	cases := []struct {
+		name   string
+		dr     *DestinationRuleWrapper
+		subset string
+		want   string
+	}{
+		{name: "no destination rule"},
+		{name: "top level policy", dr: dr, want: "x-tenant"},
+		{name: "subset policy", dr: dr, subset: "v1", want: "x-user"},
+		{name: "subset without policy", dr: dr, subset: "v2"},
+		{name: "unknown subset", dr: dr, subset: "v3"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := GetHashPolicy(c.dr, c.subset); got != c.want {
+				t.Errorf("want hash policy %q, got %q", c.want, got)
+			}
+		})
+	}
+}
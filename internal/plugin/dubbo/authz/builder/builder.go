@@ -17,82 +17,226 @@ package builder
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	dubborulepb "github.com/aeraki-mesh/api/dubbo/v1alpha1"
 	dubboapi "github.com/aeraki-mesh/client-go/pkg/apis/dubbo/v1alpha1"
-	dubboclient "github.com/aeraki-mesh/client-go/pkg/clientset/versioned/typed/dubbo/v1alpha1"
 	rbacpb "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
 	dubbopb "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/dubbo_proxy/v3"
 	rbacdubbopb "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/rbac/v3"
 	"google.golang.org/protobuf/types/known/anypb"
+	securitypb "istio.io/api/security/v1beta1"
+	typepb "istio.io/api/type/v1beta1"
+	istiomodel "istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/security/trustdomain"
+	istioconfig "istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/pkg/log"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/aeraki-mesh/aeraki/internal/envoyfilter"
 	authzmodel "github.com/aeraki-mesh/aeraki/internal/plugin/dubbo/authz/model"
 )
 
+const (
+	// dryRunAnnotation marks an authorization policy as dry-run, the same annotation as Istio uses.
+	// A dry-run policy is generated as shadow rules, which are only evaluated and reported in the stats.
+	dryRunAnnotation = "istio.io/dry-run"
+
+	dryRunDenyStatPrefix  = "dubbo_dry_run_deny_"
+	dryRunAllowStatPrefix = "dubbo_dry_run_allow_"
+)
+
 var (
 	authzLog = log.RegisterScope("authorization", "Aeraki Dubbo Authorization Policy", 0)
 )
 
+// policies holds the DubboAuthorizationPolicies and Istio AuthorizationPolicies of the same action
+type policies struct {
+	dubbo []*dubboapi.DubboAuthorizationPolicy
+	istio []*istioconfig.Config
+}
+
+func (p *policies) empty() bool {
+	return len(p.dubbo) == 0 && len(p.istio) == 0
+}
+
 // Builder builds Istio authorization policy to Envoy RBAC filter.
 type Builder struct {
 	trustDomainBundle trustdomain.Bundle
-	denyPolicies      []*dubboapi.DubboAuthorizationPolicy
-	allowPolicies     []*dubboapi.DubboAuthorizationPolicy
+	denyPolicies      policies
+	allowPolicies     policies
+	auditPolicies     policies
+	dryRunDeny        policies
+	dryRunAllow       policies
 }
 
 // New returns a new builder for the given workload with the authorization policy.
+// Both the DubboAuthorizationPolicies and the Istio AuthorizationPolicies selecting the workload are taken into
+// account, the policies in the root namespace apply to the workloads in all namespaces if rootNamespace is not empty.
+// A DubboAuthorizationPolicy selects workloads with the workloadSelector annotation, which is in the same format as
+// the one of ServiceEntry, a policy without this annotation applies to all the workloads in its namespace.
+// The workload labels are the workload selector of the ServiceEntry, a policy selecting only part of the workloads of
+// the service, e.g. app=x,version=v1 for a service selecting app=x, can't be mapped to the inbound filter of the
+// service and is ignored with a warning, because applying it to all the workloads would deny the traffic of the
+// workloads it doesn't select.
 // Returns nil if none of the authorization policies are enabled for the workload.
-func New(trustDomainBundle trustdomain.Bundle, namespace string,
-	client dubboclient.DubboV1alpha1Interface) *Builder {
-	allowPolicies := make([]*dubboapi.DubboAuthorizationPolicy, 0)
-	denyPolicies := make([]*dubboapi.DubboAuthorizationPolicy, 0)
+func New(trustDomainBundle trustdomain.Bundle, namespace, rootNamespace string, workloadLabels map[string]string,
+	c client.Client, store istiomodel.ConfigStore) *Builder {
+	b := &Builder{
+		trustDomainBundle: trustDomainBundle,
+	}
+
+	namespaces := []string{namespace}
+	if rootNamespace != "" && rootNamespace != namespace {
+		namespaces = append(namespaces, rootNamespace)
+	}
+	for _, ns := range namespaces {
+		b.addDubboPolicies(ns, workloadLabels, c)
+		b.addIstioPolicies(ns, workloadLabels, store)
+	}
+	return b
+}
 
-	dubboAuthorizationPolicyList, err := client.DubboAuthorizationPolicies(namespace).List(context.TODO(),
-		v1.ListOptions{})
+// addDubboPolicies adds the DubboAuthorizationPolicies in the namespace which select the workload
+func (b *Builder) addDubboPolicies(namespace string, workloadLabels map[string]string, c client.Client) {
+	dubboAuthorizationPolicyList := &dubboapi.DubboAuthorizationPolicyList{}
+	err := c.List(context.TODO(), dubboAuthorizationPolicyList, client.InNamespace(namespace))
 	if err != nil {
 		authzLog.Errorf("failed to list DubboAuthorizationPolicy: %v", err)
-	} else {
-		for i := range dubboAuthorizationPolicyList.Items {
-			config := dubboAuthorizationPolicyList.Items[i]
-			switch config.Spec.GetAction() {
-			case dubborulepb.DubboAuthorizationPolicy_ALLOW:
-				allowPolicies = append(allowPolicies, config)
-			case dubborulepb.DubboAuthorizationPolicy_DENY:
-				denyPolicies = append(denyPolicies, config)
-			default:
-				log.Errorf("ignored authorization policy %s.%s with unsupported action: %s",
-					config.Namespace, config.Name, config.Spec.GetAction())
+		return
+	}
+	for i := range dubboAuthorizationPolicyList.Items {
+		config := dubboAuthorizationPolicyList.Items[i]
+		if _, exist := config.Annotations[envoyfilter.WorkloadSelectorAnnotation]; exist {
+			matchLabels, err := envoyfilter.ParseWorkloadSelectorAnnotation(config.Annotations)
+			if err != nil {
+				// the policy is applied to all the workloads in its namespace so that it fails closed
+				authzLog.Errorf("invalid workloadSelector annotation of authorization policy %s.%s, "+
+					"it's applied to all the workloads: %v", config.Namespace, config.Name, err)
+			}
+			selector := &typepb.WorkloadSelector{MatchLabels: matchLabels}
+			if !selectWorkload(selector, workloadLabels, config.Namespace, config.Name) {
+				continue
+			}
+		}
+		dryRun := isDryRun(config.Annotations)
+		switch config.Spec.GetAction() {
+		case dubborulepb.DubboAuthorizationPolicy_ALLOW:
+			if dryRun {
+				b.dryRunAllow.dubbo = append(b.dryRunAllow.dubbo, config)
+			} else {
+				b.allowPolicies.dubbo = append(b.allowPolicies.dubbo, config)
+			}
+		case dubborulepb.DubboAuthorizationPolicy_DENY:
+			if dryRun {
+				b.dryRunDeny.dubbo = append(b.dryRunDeny.dubbo, config)
+			} else {
+				b.denyPolicies.dubbo = append(b.denyPolicies.dubbo, config)
 			}
+		default:
+			log.Errorf("ignored authorization policy %s.%s with unsupported action: %s",
+				config.Namespace, config.Name, config.Spec.GetAction())
 		}
 	}
+}
 
-	return &Builder{
-		trustDomainBundle: trustDomainBundle,
-		denyPolicies:      denyPolicies,
-		allowPolicies:     allowPolicies,
+// addIstioPolicies adds the Istio AuthorizationPolicies in the namespace which select the workload
+func (b *Builder) addIstioPolicies(namespace string, workloadLabels map[string]string,
+	store istiomodel.ConfigStore) {
+	if store == nil {
+		return
+	}
+	configs := store.List(gvk.AuthorizationPolicy, namespace)
+	for i := range configs {
+		config := &configs[i]
+		policy, ok := config.Spec.(*securitypb.AuthorizationPolicy)
+		if !ok { // should never happen
+			authzLog.Errorf("failed in getting an authorization policy: %s", config.Name)
+			continue
+		}
+		if !selectWorkload(policy.Selector, workloadLabels, config.Namespace, config.Name) {
+			continue
+		}
+		dryRun := isDryRun(config.Annotations)
+		switch policy.GetAction() {
+		case securitypb.AuthorizationPolicy_ALLOW:
+			if dryRun {
+				b.dryRunAllow.istio = append(b.dryRunAllow.istio, config)
+			} else {
+				b.allowPolicies.istio = append(b.allowPolicies.istio, config)
+			}
+		case securitypb.AuthorizationPolicy_DENY:
+			if dryRun {
+				b.dryRunDeny.istio = append(b.dryRunDeny.istio, config)
+			} else {
+				b.denyPolicies.istio = append(b.denyPolicies.istio, config)
+			}
+		case securitypb.AuthorizationPolicy_AUDIT:
+			if dryRun {
+				authzLog.Warnf("ignored dry-run annotation of audit policy %s.%s", config.Namespace, config.Name)
+			}
+			b.auditPolicies.istio = append(b.auditPolicies.istio, config)
+		default:
+			authzLog.Warnf("ignored authorization policy %s.%s with unsupported action for dubbo: %s",
+				config.Namespace, config.Name, policy.GetAction())
+		}
 	}
 }
 
+func isDryRun(annotations map[string]string) bool {
+	dryRun, err := strconv.ParseBool(annotations[dryRunAnnotation])
+	return err == nil && dryRun
+}
+
+// selectWorkload returns true if the selector of a policy matches the labels of the workload, an empty selector
+// matches all the workloads.
+// A selector which is narrower than the workload labels doesn't match, because the generated filter applies to all
+// the workloads of the service and can't tell them apart.
+func selectWorkload(selector *typepb.WorkloadSelector, workloadLabels map[string]string,
+	namespace, name string) bool {
+	if selector == nil || len(selector.MatchLabels) == 0 {
+		return true
+	}
+	if len(workloadLabels) == 0 {
+		return false
+	}
+	if labels.Instance(selector.MatchLabels).SubsetOf(workloadLabels) {
+		return true
+	}
+	if labels.Instance(workloadLabels).SubsetOf(selector.MatchLabels) {
+		authzLog.Warnf("ignored authorization policy %s.%s, it selects %v, which is only part of the workloads %v "+
+			"of the service", namespace, name, selector.MatchLabels, workloadLabels)
+	}
+	return false
+}
+
 // BuildDubboFilter returns the RBAC TCP filters built from the authorization policy.
+// The audit filter comes first so that the requests are logged even if they are denied later, the deny filter is
+// evaluated before the allow filter. Dry-run policies are added to the filter of the same action as shadow rules.
 func (b Builder) BuildDubboFilter() []*dubbopb.DubboFilter {
 	filters := make([]*dubbopb.DubboFilter, 0)
 
-	if denyConfig := build(b.denyPolicies, b.trustDomainBundle, rbacpb.RBAC_DENY); denyConfig != nil {
-		filters = append(filters, createDubboRBACFilter(denyConfig))
+	if auditConfig := build(b.auditPolicies, b.trustDomainBundle, rbacpb.RBAC_LOG); auditConfig != nil {
+		filters = append(filters, createDubboRBACFilter(auditConfig, nil, ""))
+	}
+	denyConfig := build(b.denyPolicies, b.trustDomainBundle, rbacpb.RBAC_DENY)
+	dryRunDenyConfig := build(b.dryRunDeny, b.trustDomainBundle, rbacpb.RBAC_DENY)
+	if denyConfig != nil || dryRunDenyConfig != nil {
+		filters = append(filters, createDubboRBACFilter(denyConfig, dryRunDenyConfig, dryRunDenyStatPrefix))
 	}
-	if allowConfig := build(b.allowPolicies, b.trustDomainBundle, rbacpb.RBAC_ALLOW); allowConfig != nil {
-		filters = append(filters, createDubboRBACFilter(allowConfig))
+	allowConfig := build(b.allowPolicies, b.trustDomainBundle, rbacpb.RBAC_ALLOW)
+	dryRunAllowConfig := build(b.dryRunAllow, b.trustDomainBundle, rbacpb.RBAC_ALLOW)
+	if allowConfig != nil || dryRunAllowConfig != nil {
+		filters = append(filters, createDubboRBACFilter(allowConfig, dryRunAllowConfig, dryRunAllowStatPrefix))
 	}
 
 	return filters
 }
 
-func build(policies []*dubboapi.DubboAuthorizationPolicy, tdBundle trustdomain.Bundle,
-	action rbacpb.RBAC_Action) *rbacpb.RBAC {
-	if len(policies) == 0 {
+func build(p policies, tdBundle trustdomain.Bundle, action rbacpb.RBAC_Action) *rbacpb.RBAC {
+	if p.empty() {
 		return nil
 	}
 
@@ -101,9 +245,9 @@ func build(policies []*dubboapi.DubboAuthorizationPolicy, tdBundle trustdomain.B
 		Policies: map[string]*rbacpb.Policy{},
 	}
 
-	for i := range policies {
-		for i, rule := range policies[i].Spec.Rules {
-			name := fmt.Sprintf("ns[%s]-policy[%s]-rule[%d]", policies[i].Namespace, policies[i].Name, i)
+	for i := range p.dubbo {
+		for j, rule := range p.dubbo[i].Spec.Rules {
+			name := fmt.Sprintf("ns[%s]-policy[%s]-rule[%d]", p.dubbo[i].Namespace, p.dubbo[i].Name, j)
 			if rule == nil {
 				authzLog.Errorf("skipped nil rule %s", name)
 				continue
@@ -113,24 +257,51 @@ func build(policies []*dubboapi.DubboAuthorizationPolicy, tdBundle trustdomain.B
 				authzLog.Errorf("skipped rule %s: %v", name, err)
 				continue
 			}
-			m.MigrateTrustDomain(tdBundle)
-			generated, err := m.Generate(action)
+			generate(rules, name, m, tdBundle, action)
+		}
+	}
+
+	for i := range p.istio {
+		policy := p.istio[i].Spec.(*securitypb.AuthorizationPolicy)
+		for j, rule := range policy.Rules {
+			name := fmt.Sprintf("istio-ns[%s]-policy[%s]-rule[%d]", p.istio[i].Namespace, p.istio[i].Name, j)
+			if rule == nil {
+				authzLog.Errorf("skipped nil rule %s", name)
+				continue
+			}
+			m, err := authzmodel.NewFromIstio(rule)
 			if err != nil {
 				authzLog.Errorf("skipped rule %s: %v", name, err)
 				continue
 			}
-			if generated != nil {
-				rules.Policies[name] = generated
-				authzLog.Debugf("rule %s generated policy: %+v", name, generated)
+			if unsupported := m.UnsupportedFields(); len(unsupported) > 0 {
+				authzLog.Warnf("rule %s has fields which can't be applied to dubbo traffic: %v", name, unsupported)
 			}
+			generate(rules, name, m, tdBundle, action)
 		}
 	}
 
 	return rules
 }
 
-func createDubboRBACFilter(config *rbacpb.RBAC) *dubbopb.DubboFilter {
-	if config == nil {
+func generate(rules *rbacpb.RBAC, name string, m *authzmodel.Model, tdBundle trustdomain.Bundle,
+	action rbacpb.RBAC_Action) {
+	m.MigrateTrustDomain(tdBundle)
+	generated, err := m.Generate(action)
+	if err != nil {
+		authzLog.Errorf("skipped rule %s: %v", name, err)
+		return
+	}
+	if generated != nil {
+		rules.Policies[name] = generated
+		authzLog.Debugf("rule %s generated policy: %+v", name, generated)
+	}
+}
+
+// createDubboRBACFilter creates a Dubbo RBAC filter, either config or shadowConfig can be nil.
+func createDubboRBACFilter(config *rbacpb.RBAC, shadowConfig *rbacpb.RBAC,
+	shadowStatPrefix string) *dubbopb.DubboFilter {
+	if config == nil && shadowConfig == nil {
 		return nil
 	}
 
@@ -138,6 +309,10 @@ func createDubboRBACFilter(config *rbacpb.RBAC) *dubbopb.DubboFilter {
 		Rules:      config,
 		StatPrefix: authzmodel.RBACDubboFilterStatPrefix,
 	}
+	if shadowConfig != nil {
+		rbacConfig.ShadowRules = shadowConfig
+		rbacConfig.ShadowRulesStatPrefix = shadowStatPrefix
+	}
 	rbacPolicyInAny, _ := anypb.New(rbacConfig)
 	return &dubbopb.DubboFilter{
 		Name:   authzmodel.RBACDUBBOFilterName,
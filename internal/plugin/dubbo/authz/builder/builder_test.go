@@ -0,0 +1,334 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"sort"
+	"testing"
+
+	dubborulepb "github.com/aeraki-mesh/api/dubbo/v1alpha1"
+	dubboapi "github.com/aeraki-mesh/client-go/pkg/apis/dubbo/v1alpha1"
+	aerakischeme "github.com/aeraki-mesh/client-go/pkg/clientset/versioned/scheme"
+	rbacpb "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	dubbopb "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/dubbo_proxy/v3"
+	rbacdubbopb "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/rbac/v3"
+	"github.com/google/go-cmp/cmp"
+	securitypb "istio.io/api/security/v1beta1"
+	typepb "istio.io/api/type/v1beta1"
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/security/trustdomain"
+	istioconfig "istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/istio/pkg/config/schema/gvk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testNamespace     = "dubbo"
+	testRootNamespace = "istio-system"
+)
+
+var testWorkloadLabels = map[string]string{"app": "dubbo-sample-provider"}
+
+// filterRules is a summary of a generated RBAC filter, the names of the rules and shadow rules
+type filterRules struct {
+	Action       rbacpb.RBAC_Action
+	Rules        []string
+	ShadowRules  []string
+	ShadowPrefix string
+}
+
+func newTestBuilder(t *testing.T, rootNamespace string, dubboPolicies []client.Object,
+	istioPolicies []istioconfig.Config) *Builder {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := aerakischeme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dubboPolicies...).Build()
+	store := memory.MakeSkipValidation(collections.Pilot)
+	for _, policy := range istioPolicies {
+		if _, err := store.Create(policy); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return New(trustdomain.NewBundle("cluster.local", nil), testNamespace, rootNamespace, testWorkloadLabels,
+		c, store)
+}
+
+func istioPolicy(name, namespace string, action securitypb.AuthorizationPolicy_Action,
+	selector map[string]string, annotations map[string]string) istioconfig.Config {
+	policy := &securitypb.AuthorizationPolicy{
+		Action: action,
+		Rules: []*securitypb.Rule{{
+			From: []*securitypb.Rule_From{{
+				Source: &securitypb.Source{Principals: []string{"cluster.local/ns/dubbo/sa/consumer"}},
+			}},
+		}},
+	}
+	if selector != nil {
+		policy.Selector = &typepb.WorkloadSelector{MatchLabels: selector}
+	}
+	return istioconfig.Config{
+		Meta: istioconfig.Meta{
+			GroupVersionKind: gvk.AuthorizationPolicy,
+			Name:             name,
+			Namespace:        namespace,
+			Annotations:      annotations,
+		},
+		Spec: policy,
+	}
+}
+
+func dubboPolicy(name, namespace string, action dubborulepb.DubboAuthorizationPolicy_Action,
+	annotations map[string]string) client.Object {
+	return &dubboapi.DubboAuthorizationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Annotations: annotations},
+		Spec: dubborulepb.DubboAuthorizationPolicy{
+			Action: action,
+			Rules: []*dubborulepb.Rule{{
+				From: []*dubborulepb.Rule_From{{
+					Source: &dubborulepb.Source{Principals: []string{"cluster.local/ns/dubbo/sa/consumer"}},
+				}},
+			}},
+		},
+	}
+}
+
+func summarize(t *testing.T, filters []*dubbopb.DubboFilter) []filterRules {
+	t.Helper()
+	got := make([]filterRules, 0, len(filters))
+	for _, filter := range filters {
+		rbac := &rbacdubbopb.RBAC{}
+		if err := filter.Config.UnmarshalTo(rbac); err != nil {
+			t.Fatal(err)
+		}
+		summary := filterRules{ShadowPrefix: rbac.ShadowRulesStatPrefix}
+		if rbac.Rules != nil {
+			summary.Action = rbac.Rules.Action
+			summary.Rules = policyNames(rbac.Rules)
+		}
+		if rbac.ShadowRules != nil {
+			summary.Action = rbac.ShadowRules.Action
+			summary.ShadowRules = policyNames(rbac.ShadowRules)
+		}
+		got = append(got, summary)
+	}
+	return got
+}
+
+func policyNames(rbac *rbacpb.RBAC) []string {
+	names := make([]string, 0, len(rbac.Policies))
+	for name := range rbac.Policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestBuildDubboFilterSelector(t *testing.T) {
+	cases := []struct {
+		name          string
+		rootNamespace string
+		dubboPolicies []client.Object
+		istioPolicies []istioconfig.Config
+		want          []filterRules
+	}{
+		{
+			name: "allow",
+			istioPolicies: []istioconfig.Config{
+				istioPolicy("allow", testNamespace, securitypb.AuthorizationPolicy_ALLOW, testWorkloadLabels, nil),
+			},
+			want: []filterRules{{
+				Action: rbacpb.RBAC_ALLOW,
+				Rules:  []string{"istio-ns[dubbo]-policy[allow]-rule[0]"},
+			}},
+		},
+		{
+			name: "deny",
+			dubboPolicies: []client.Object{
+				dubboPolicy("deny", testNamespace, dubborulepb.DubboAuthorizationPolicy_DENY,
+					map[string]string{"workloadSelector": "dubbo-sample-provider"}),
+			},
+			want: []filterRules{{
+				Action: rbacpb.RBAC_DENY,
+				Rules:  []string{"ns[dubbo]-policy[deny]-rule[0]"},
+			}},
+		},
+		{
+			name: "selector mismatch",
+			dubboPolicies: []client.Object{
+				dubboPolicy("deny", testNamespace, dubborulepb.DubboAuthorizationPolicy_DENY,
+					map[string]string{"workloadSelector": "other"}),
+			},
+			istioPolicies: []istioconfig.Config{
+				istioPolicy("allow", testNamespace, securitypb.AuthorizationPolicy_ALLOW,
+					map[string]string{"app": "other"}, nil),
+				istioPolicy("other-namespace", "other", securitypb.AuthorizationPolicy_DENY, nil, nil),
+			},
+			want: []filterRules{},
+		},
+		{
+			name: "selector narrower than the service",
+			istioPolicies: []istioconfig.Config{
+				istioPolicy("deny-v1", testNamespace, securitypb.AuthorizationPolicy_DENY,
+					map[string]string{"app": "dubbo-sample-provider", "version": "v1"}, nil),
+				istioPolicy("allow-v1", testNamespace, securitypb.AuthorizationPolicy_ALLOW,
+					map[string]string{"app": "dubbo-sample-provider", "version": "v1"}, nil),
+			},
+			want: []filterRules{},
+		},
+		{
+			name: "invalid workload selector",
+			dubboPolicies: []client.Object{
+				dubboPolicy("deny", testNamespace, dubborulepb.DubboAuthorizationPolicy_DENY,
+					map[string]string{"workloadSelector": ""}),
+			},
+			want: []filterRules{{
+				Action: rbacpb.RBAC_DENY,
+				Rules:  []string{"ns[dubbo]-policy[deny]-rule[0]"},
+			}},
+		},
+		{
+			name: "root namespace disabled",
+			istioPolicies: []istioconfig.Config{
+				istioPolicy("deny", testRootNamespace, securitypb.AuthorizationPolicy_DENY, nil, nil),
+			},
+			want: []filterRules{},
+		},
+		{
+			name:          "root namespace",
+			rootNamespace: testRootNamespace,
+			istioPolicies: []istioconfig.Config{
+				istioPolicy("deny", testRootNamespace, securitypb.AuthorizationPolicy_DENY, nil, nil),
+			},
+			want: []filterRules{{
+				Action: rbacpb.RBAC_DENY,
+				Rules:  []string{"istio-ns[istio-system]-policy[deny]-rule[0]"},
+			}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := newTestBuilder(t, c.rootNamespace, c.dubboPolicies, c.istioPolicies)
+			got := summarize(t, b.BuildDubboFilter())
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("unexpected filters (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBuildDubboFilterDryRunAndAudit(t *testing.T) {
+	dryRun := map[string]string{dryRunAnnotation: "true"}
+	cases := []struct {
+		name          string
+		dubboPolicies []client.Object
+		istioPolicies []istioconfig.Config
+		want          []filterRules
+	}{
+		{
+			name: "dry-run deny",
+			dubboPolicies: []client.Object{
+				dubboPolicy("deny", testNamespace, dubborulepb.DubboAuthorizationPolicy_DENY, dryRun),
+			},
+			want: []filterRules{{
+				Action:       rbacpb.RBAC_DENY,
+				ShadowRules:  []string{"ns[dubbo]-policy[deny]-rule[0]"},
+				ShadowPrefix: dryRunDenyStatPrefix,
+			}},
+		},
+		{
+			name: "dry-run allow",
+			istioPolicies: []istioconfig.Config{
+				istioPolicy("allow", testNamespace, securitypb.AuthorizationPolicy_ALLOW, nil, dryRun),
+			},
+			want: []filterRules{{
+				Action:       rbacpb.RBAC_ALLOW,
+				ShadowRules:  []string{"istio-ns[dubbo]-policy[allow]-rule[0]"},
+				ShadowPrefix: dryRunAllowStatPrefix,
+			}},
+		},
+		{
+			name: "dry-run and enforced policies of the same action",
+			istioPolicies: []istioconfig.Config{
+				istioPolicy("deny", testNamespace, securitypb.AuthorizationPolicy_DENY, nil, nil),
+				istioPolicy("deny-dry-run", testNamespace, securitypb.AuthorizationPolicy_DENY, nil, dryRun),
+			},
+			want: []filterRules{{
+				Action:       rbacpb.RBAC_DENY,
+				Rules:        []string{"istio-ns[dubbo]-policy[deny]-rule[0]"},
+				ShadowRules:  []string{"istio-ns[dubbo]-policy[deny-dry-run]-rule[0]"},
+				ShadowPrefix: dryRunDenyStatPrefix,
+			}},
+		},
+		{
+			name: "audit comes before deny and allow",
+			istioPolicies: []istioconfig.Config{
+				istioPolicy("allow", testNamespace, securitypb.AuthorizationPolicy_ALLOW, nil, nil),
+				istioPolicy("deny", testNamespace, securitypb.AuthorizationPolicy_DENY, nil, nil),
+				// the dry-run annotation of an audit policy is ignored
+				istioPolicy("audit", testNamespace, securitypb.AuthorizationPolicy_AUDIT, nil, dryRun),
+			},
+			want: []filterRules{
+				{
+					Action: rbacpb.RBAC_LOG,
+					Rules:  []string{"istio-ns[dubbo]-policy[audit]-rule[0]"},
+				},
+				{
+					Action: rbacpb.RBAC_DENY,
+					Rules:  []string{"istio-ns[dubbo]-policy[deny]-rule[0]"},
+				},
+				{
+					Action: rbacpb.RBAC_ALLOW,
+					Rules:  []string{"istio-ns[dubbo]-policy[allow]-rule[0]"},
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := newTestBuilder(t, "", c.dubboPolicies, c.istioPolicies)
+			got := summarize(t, b.BuildDubboFilter())
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("unexpected filters (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateDubboRBACFilter(t *testing.T) {
+	if got := createDubboRBACFilter(nil, nil, dryRunDenyStatPrefix); got != nil {
+		t.Errorf("expected no filter without rules, got %v", got)
+	}
+
+	shadow := &rbacpb.RBAC{Action: rbacpb.RBAC_ALLOW}
+	filter := createDubboRBACFilter(nil, shadow, dryRunAllowStatPrefix)
+	rbac := &rbacdubbopb.RBAC{}
+	if err := filter.Config.UnmarshalTo(rbac); err != nil {
+		t.Fatal(err)
+	}
+	if rbac.Rules != nil {
+		t.Errorf("expected no enforced rules for a dry-run only filter, got %v", rbac.Rules)
+	}
+	if rbac.ShadowRulesStatPrefix != dryRunAllowStatPrefix {
+		t.Errorf("unexpected shadow rules stat prefix: %s", rbac.ShadowRulesStatPrefix)
+	}
+}
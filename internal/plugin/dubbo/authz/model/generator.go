@@ -53,6 +53,141 @@ func (methodGenerator) principal(_, _ string) (*rbacpb.Principal, error) {
 	return nil, fmt.Errorf("unimplemented")
 }
 
+type pathGenerator struct {
+}
+
+// permission matches a path in the format of "/<interface>/<method>", a missing or wildcard ("*") method matches all
+// the methods of the interface.
+func (pathGenerator) permission(_, value string) (*rbacpb.Permission, error) {
+	iface, method, _ := strings.Cut(strings.TrimPrefix(value, "/"), "/")
+	if iface == "" {
+		return nil, fmt.Errorf("invalid dubbo path %q, must be in the format of /<interface>/<method>", value)
+	}
+	var and []*rbacpb.Permission
+	if iface != "*" {
+		p, _ := interfaceGenerator{}.permission(dubboInterface, iface)
+		and = append(and, p)
+	}
+	if method != "" && method != "*" {
+		p, _ := methodGenerator{}.permission(dubboMethod, method)
+		and = append(and, p)
+	}
+	if len(and) == 0 {
+		return permissionAny(), nil
+	}
+	return permissionAnd(and), nil
+}
+
+func (pathGenerator) principal(_, _ string) (*rbacpb.Principal, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+type srcIPGenerator struct {
+}
+
+func (srcIPGenerator) permission(_, _ string) (*rbacpb.Permission, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (srcIPGenerator) principal(_, value string) (*rbacpb.Principal, error) {
+	cidr, err := matcher.CidrRange(value)
+	if err != nil {
+		return nil, err
+	}
+	return principalDirectRemoteIP(cidr), nil
+}
+
+type remoteIPGenerator struct {
+}
+
+func (remoteIPGenerator) permission(_, _ string) (*rbacpb.Permission, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (remoteIPGenerator) principal(_, value string) (*rbacpb.Principal, error) {
+	cidr, err := matcher.CidrRange(value)
+	if err != nil {
+		return nil, err
+	}
+	return principalRemoteIP(cidr), nil
+}
+
+// requestHeaderGenerator matches the headers of a request, the attachments of a Dubbo request are exposed as
+// headers to the Dubbo RBAC filter
+type requestHeaderGenerator struct {
+}
+
+func (requestHeaderGenerator) permission(_, _ string) (*rbacpb.Permission, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (requestHeaderGenerator) principal(key, value string) (*rbacpb.Principal, error) {
+	header, err := extractNameInBrackets(strings.TrimPrefix(key, attrRequestHeader))
+	if err != nil {
+		return nil, err
+	}
+	m := matcher.HeaderMatcher(header, value)
+	return principalHeader(m), nil
+}
+
+// metadataGenerator matches the dynamic metadata of a filter, the key is in the format of
+// "experimental.envoy.filters.<filter name>[<key1>][<key2>]...", e.g.
+// "experimental.envoy.filters.dubbo.rbac[tenant]".
+type metadataGenerator struct {
+}
+
+func (metadataGenerator) permission(key, value string) (*rbacpb.Permission, error) {
+	filter, path, found := strings.Cut(strings.TrimPrefix(key, attrExperimental), "[")
+	if !found || filter == "" {
+		return nil, fmt.Errorf("invalid metadata key %q", key)
+	}
+	keys, err := extractNamesInBrackets("[" + path)
+	if err != nil {
+		return nil, err
+	}
+	m := matcher.MetadataListMatcher("envoy.filters."+filter, keys, value)
+	return permissionMetadata(m), nil
+}
+
+func (metadataGenerator) principal(_, _ string) (*rbacpb.Principal, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+// extractNameInBrackets extracts the name from a string in the format of "[name]"
+func extractNameInBrackets(s string) (string, error) {
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") || len(s) < 3 {
+		return "", fmt.Errorf("expecting format [<NAME>], but found %s", s)
+	}
+	return strings.TrimPrefix(strings.TrimSuffix(s, "]"), "["), nil
+}
+
+// extractNamesInBrackets extracts the names from a string in the format of "[name1][name2]..."
+func extractNamesInBrackets(s string) ([]string, error) {
+	name, err := extractNameInBrackets(s)
+	if err != nil {
+		return nil, err
+	}
+	names := strings.Split(name, "][")
+	for _, n := range names {
+		if n == "" {
+			return nil, fmt.Errorf("expecting format [<NAME>][<NAME>]..., but found %s", s)
+		}
+	}
+	return names, nil
+}
+
+// unsupportedGenerator is used for the fields which can't be applied to Dubbo traffic
+type unsupportedGenerator struct {
+}
+
+func (unsupportedGenerator) permission(key, _ string) (*rbacpb.Permission, error) {
+	return nil, fmt.Errorf("%s is not supported for dubbo", key)
+}
+
+func (unsupportedGenerator) principal(key, _ string) (*rbacpb.Principal, error) {
+	return nil, fmt.Errorf("%s is not supported for dubbo", key)
+}
+
 type srcNamespaceGenerator struct {
 }
 
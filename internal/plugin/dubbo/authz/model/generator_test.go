@@ -0,0 +1,119 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	corepb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	rbacpb "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/aeraki-mesh/aeraki/internal/plugin/dubbo/authz/matcher"
+)
+
+func TestGenerator(t *testing.T) {
+	cases := []struct {
+		name           string
+		g              generator
+		key            string
+		value          string
+		wantPermission *rbacpb.Permission
+		wantPrincipal  *rbacpb.Principal
+		wantErr        bool
+	}{
+		{
+			name:  "pathGenerator",
+			g:     pathGenerator{},
+			value: "/org.apache.dubbo.samples.basic.api.DemoService/sayHello",
+			wantPermission: permissionAnd([]*rbacpb.Permission{
+				permissionMetadata(matcher.MetadataStringMatcher("envoy.filters.dubbo.rbac", "service",
+					matcher.StringMatcher("org.apache.dubbo.samples.basic.api.DemoService"))),
+				permissionMetadata(matcher.MetadataStringMatcher("envoy.filters.dubbo.rbac", "method",
+					matcher.StringMatcher("sayHello"))),
+			}),
+		},
+		{
+			name:  "pathGeneratorAllMethods",
+			g:     pathGenerator{},
+			value: "/org.apache.dubbo.samples.basic.api.DemoService/*",
+			wantPermission: permissionAnd([]*rbacpb.Permission{
+				permissionMetadata(matcher.MetadataStringMatcher("envoy.filters.dubbo.rbac", "service",
+					matcher.StringMatcher("org.apache.dubbo.samples.basic.api.DemoService"))),
+			}),
+		},
+		{
+			name:    "pathGeneratorInvalid",
+			g:       pathGenerator{},
+			value:   "/",
+			wantErr: true,
+		},
+		{
+			name:  "srcIPGenerator",
+			g:     srcIPGenerator{},
+			value: "10.1.0.0/16",
+			wantPrincipal: principalDirectRemoteIP(&corepb.CidrRange{
+				AddressPrefix: "10.1.0.0",
+				PrefixLen:     &wrappers.UInt32Value{Value: 16},
+			}),
+		},
+		{
+			name:          "requestHeaderGenerator",
+			g:             requestHeaderGenerator{},
+			key:           "request.headers[x-tenant]",
+			value:         "foo",
+			wantPrincipal: principalHeader(matcher.HeaderMatcher("x-tenant", "foo")),
+		},
+		{
+			name:    "requestHeaderGeneratorInvalid",
+			g:       requestHeaderGenerator{},
+			key:     "request.headers[]",
+			value:   "foo",
+			wantErr: true,
+		},
+		{
+			name:  "metadataGenerator",
+			g:     metadataGenerator{},
+			key:   "experimental.envoy.filters.dubbo.rbac[tenant][id]",
+			value: "foo",
+			wantPermission: permissionMetadata(matcher.MetadataListMatcher("envoy.filters.dubbo.rbac",
+				[]string{"tenant", "id"}, "foo")),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var err error
+			if tc.wantPrincipal != nil || (tc.wantErr && tc.key != "") {
+				var actual *rbacpb.Principal
+				actual, err = tc.g.principal(tc.key, tc.value)
+				if err == nil && !cmp.Equal(actual, tc.wantPrincipal, protocmp.Transform()) {
+					t.Errorf("want %s, got %s", tc.wantPrincipal.String(), actual.String())
+				}
+			} else {
+				var actual *rbacpb.Permission
+				actual, err = tc.g.permission(tc.key, tc.value)
+				if err == nil && !cmp.Equal(actual, tc.wantPermission, protocmp.Transform()) {
+					t.Errorf("want %s, got %s", tc.wantPermission.String(), actual.String())
+				}
+			}
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"strings"
+
+	securitypb "istio.io/api/security/v1beta1"
+)
+
+const (
+	dubboPath = "dubboPath"
+
+	attrSrcIP            = "source.ip"              // supports both single IP and CIDR, e.g. "10.1.2.3" or "10.1.0.0/16".
+	attrRemoteIP         = "remote.ip"              // original client ip determined from proxy protocol.
+	attrRequestPrincipal = "request.auth.principal" // authenticated principal of the request.
+	attrOperationHost    = "operation.host"
+	attrOperationPort    = "operation.port"
+	attrOperationMethod  = "operation.method"
+	// header name is surrounded by brackets, e.g. "request.headers[User-Agent]".
+	attrRequestHeader = "request.headers"
+	// dynamic metadata of a filter, e.g. "experimental.envoy.filters.dubbo.rbac[tenant]".
+	attrExperimental = "experimental.envoy.filters."
+)
+
+// NewFromIstio returns a model representing a single rule of an Istio authorization policy.
+// The paths of an operation are translated to Dubbo interfaces and methods in the format of "/<interface>/<method>",
+// conditions on source IP, remote IP, request headers (Dubbo attachments) and filter metadata are supported,
+// the fields which can't be applied to Dubbo traffic are kept in the model and recorded as unsupported, the
+// generation of a rule with unsupported fields is handled the same way as Istio does for TCP traffic: an ALLOW rule
+// is ignored, and the unsupported fields of a DENY rule are ignored.
+func NewFromIstio(r *securitypb.Rule) (*Model, error) {
+	m := Model{}
+
+	basePermission := ruleList{}
+	basePrincipal := ruleList{}
+
+	for _, when := range r.When {
+		k := when.Key
+		switch {
+		case k == attrSrcNamespace:
+			basePrincipal.insertFront(srcNamespaceGenerator{}, k, when.Values, when.NotValues)
+		case k == attrSrcPrincipal:
+			basePrincipal.insertFront(srcPrincipalGenerator{}, k, when.Values, when.NotValues)
+		case k == attrSrcIP:
+			basePrincipal.insertFront(srcIPGenerator{}, k, when.Values, when.NotValues)
+		case k == attrRemoteIP:
+			basePrincipal.insertFront(remoteIPGenerator{}, k, when.Values, when.NotValues)
+		case strings.HasPrefix(k, attrRequestHeader):
+			basePrincipal.insertFront(requestHeaderGenerator{}, k, when.Values, when.NotValues)
+		case strings.HasPrefix(k, attrExperimental):
+			basePermission.insertFront(metadataGenerator{}, k, when.Values, when.NotValues)
+		default:
+			m.unsupported(&basePermission, k, when.Values, when.NotValues)
+		}
+	}
+
+	for _, from := range r.From {
+		merged := basePrincipal.copy()
+		if s := from.Source; s != nil {
+			merged.insertFront(srcNamespaceGenerator{}, attrSrcNamespace, s.Namespaces, s.NotNamespaces)
+			merged.insertFront(srcPrincipalGenerator{}, attrSrcPrincipal, s.Principals, s.NotPrincipals)
+			merged.insertFront(srcIPGenerator{}, attrSrcIP, s.IpBlocks, s.NotIpBlocks)
+			merged.insertFront(remoteIPGenerator{}, attrRemoteIP, s.RemoteIpBlocks, s.NotRemoteIpBlocks)
+			m.unsupported(&merged, attrRequestPrincipal, s.RequestPrincipals, s.NotRequestPrincipals)
+		}
+		m.principals = append(m.principals, merged)
+	}
+	if len(r.From) == 0 {
+		m.principals = append(m.principals, basePrincipal)
+	}
+
+	for _, to := range r.To {
+		merged := basePermission.copy()
+		if o := to.Operation; o != nil {
+			merged.insertFront(pathGenerator{}, dubboPath, o.Paths, o.NotPaths)
+			m.unsupported(&merged, attrOperationHost, o.Hosts, o.NotHosts)
+			m.unsupported(&merged, attrOperationPort, o.Ports, o.NotPorts)
+			m.unsupported(&merged, attrOperationMethod, o.Methods, o.NotMethods)
+		}
+		m.permissions = append(m.permissions, merged)
+	}
+	if len(r.To) == 0 {
+		m.permissions = append(m.permissions, basePermission)
+	}
+
+	return &m, nil
+}
+
+// UnsupportedFields returns the fields of the rule which can't be applied to Dubbo traffic.
+func (m *Model) UnsupportedFields() []string {
+	return m.unsupportedFields
+}
+
+func (m *Model) unsupported(rl *ruleList, key string, values, notValues []string) {
+	if len(values) == 0 && len(notValues) == 0 {
+		return
+	}
+	m.unsupportedFields = append(m.unsupportedFields, key)
+	rl.insertFront(unsupportedGenerator{}, key, values, notValues)
+}
@@ -53,6 +53,8 @@ type ruleList struct {
 type Model struct {
 	permissions []ruleList
 	principals  []ruleList
+
+	unsupportedFields []string
 }
 
 // New returns a model representing a single authorization policy.
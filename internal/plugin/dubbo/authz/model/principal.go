@@ -15,7 +15,9 @@
 package model
 
 import (
+	corepb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	rbacpb "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	routepb "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	matcherpb "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 )
 
@@ -64,3 +66,27 @@ func principalAuthenticated(name *matcherpb.StringMatcher) *rbacpb.Principal {
 		},
 	}
 }
+
+func principalDirectRemoteIP(cidr *corepb.CidrRange) *rbacpb.Principal {
+	return &rbacpb.Principal{
+		Identifier: &rbacpb.Principal_DirectRemoteIp{
+			DirectRemoteIp: cidr,
+		},
+	}
+}
+
+func principalRemoteIP(cidr *corepb.CidrRange) *rbacpb.Principal {
+	return &rbacpb.Principal{
+		Identifier: &rbacpb.Principal_RemoteIp{
+			RemoteIp: cidr,
+		},
+	}
+}
+
+func principalHeader(header *routepb.HeaderMatcher) *rbacpb.Principal {
+	return &rbacpb.Principal{
+		Identifier: &rbacpb.Principal_Header{
+			Header: header,
+		},
+	}
+}
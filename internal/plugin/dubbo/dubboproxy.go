@@ -15,20 +15,21 @@
 package dubbo
 
 import (
-	dubbov1alpha1 "github.com/aeraki-mesh/client-go/pkg/clientset/versioned/typed/dubbo/v1alpha1"
 	dubbo "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/dubbo_proxy/v3"
+	istiomodel "istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/security/trustdomain"
 	"istio.io/istio/pkg/spiffe"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/aeraki-mesh/aeraki/internal/envoyfilter"
 	"github.com/aeraki-mesh/aeraki/internal/model"
 	"github.com/aeraki-mesh/aeraki/internal/plugin/dubbo/authz/builder"
 )
 
-func buildOutboundProxy(context *model.EnvoyFilterContext) *dubbo.DubboProxy {
-	route, err := buildOutboundRouteConfig(context)
+func buildOutboundProxy(context *model.EnvoyFilterContext) (*dubbo.DubboProxy, error) {
+	routes, err := buildOutboundRouteConfigs(context)
 	if err != nil {
-		generatorLog.Errorf("Failed to generate Dubbo EnvoyFilter: %v, %v", context.ServiceEntry, err)
-		return nil
+		return nil, err
 	}
 
 	return &dubbo.DubboProxy{
@@ -36,26 +37,25 @@ func buildOutboundProxy(context *model.EnvoyFilterContext) *dubbo.DubboProxy {
 			context.ServiceEntry.Spec.Hosts[0], int(context.ServiceEntry.Spec.Ports[0].Number)),
 		ProtocolType:      dubbo.ProtocolType_Dubbo,
 		SerializationType: dubbo.SerializationType_Hessian2,
-		// we only support one to one mapping of interface and service. If there're multiple interfaces in one process,
-		// these interfaces can be defined in separated services, one service for one interface.
-		RouteConfig: []*dubbo.RouteConfiguration{
-			route,
-		},
+		// multiple interfaces exported on the same port can be declared in the interface annotation of the service,
+		// there is a route configuration for each interface
+		RouteConfig: routes,
 		DubboFilters: []*dubbo.DubboFilter{
 			{
 				Name: "envoy.filters.dubbo.router",
 			},
 		},
-	}
+	}, nil
 }
 
-func buildInboundProxy(context *model.EnvoyFilterContext,
-	client dubbov1alpha1.DubboV1alpha1Interface) *dubbo.DubboProxy {
+func buildInboundProxy(context *model.EnvoyFilterContext, c client.Client,
+	store istiomodel.ConfigStore, rootNamespace string) *dubbo.DubboProxy {
 	route := buildInboundRouteConfig(context)
 
 	// Todo support Domain alias
 	tdBundle := trustdomain.NewBundle(spiffe.GetTrustDomain(), []string{})
-	builder := builder.New(tdBundle, context.ServiceEntry.Namespace, client)
+	builder := builder.New(tdBundle, context.ServiceEntry.Namespace, rootNamespace,
+		envoyfilter.InboundWorkloadLabels(context.ServiceEntry), c, store)
 	dubboFilters := builder.BuildDubboFilter()
 	dubboFilters = append(dubboFilters, &dubbo.DubboFilter{
 		Name: "envoy.filters.dubbo.router",
@@ -66,8 +66,7 @@ func buildInboundProxy(context *model.EnvoyFilterContext,
 			context.ServiceEntry.Spec.Hosts[0], int(context.ServiceEntry.Spec.Ports[0].Number)),
 		ProtocolType:      dubbo.ProtocolType_Dubbo,
 		SerializationType: dubbo.SerializationType_Hessian2,
-		// we only support one to one mapping of interface and service. If there're multiple interfaces in one process,
-		// these interfaces can be defined in separated services, one service for one interface.
+		// the inbound route configuration catches all the interfaces exported on this port
 		RouteConfig: []*dubbo.RouteConfiguration{
 			route,
 		},
@@ -15,10 +15,10 @@
 package dubbo
 
 import (
-	"github.com/aeraki-mesh/client-go/pkg/clientset/versioned"
-	dubbov1alpha1 "github.com/aeraki-mesh/client-go/pkg/clientset/versioned/typed/dubbo/v1alpha1"
+	"google.golang.org/protobuf/proto"
+	istiomodel "istio.io/istio/pilot/pkg/model"
 	"istio.io/pkg/log"
-	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/aeraki-mesh/aeraki/internal/envoyfilter"
 	"github.com/aeraki-mesh/aeraki/internal/model"
@@ -28,28 +28,43 @@ var generatorLog = log.RegisterScope("dubbo-generator", "dubbo generator", 0)
 
 // Generator defines a dubbo envoyfilter Generator
 type Generator struct {
-	client dubbov1alpha1.DubboV1alpha1Interface
+	client client.Client
+	store  istiomodel.ConfigStore
+	// the authorization policies in the root namespace apply to all the dubbo services, disabled if it's empty
+	rootNamespace string
 }
 
 // NewGenerator creates an new Dubbo Generator instance
-func NewGenerator(cfg *rest.Config) *Generator {
-	clientset, err := versioned.NewForConfig(cfg)
-	if err != nil {
-		generatorLog.Fatalf("Could not create clientset: %e", err)
-	}
-
+// The client is expected to be backed by the informer cache of a controller manager.
+func NewGenerator(c client.Client, store istiomodel.ConfigStore, rootNamespace string) *Generator {
 	return &Generator{
-		client: clientset.DubboV1alpha1(),
+		client:        c,
+		store:         store,
+		rootNamespace: rootNamespace,
 	}
 }
 
 // Generate create EnvoyFilters for Dubbo services
+// Only hessian2 serialization is supported by Envoy's dubbo_proxy. A service declaring another serialization still
+// gets the hessian2 dubbo_proxy, so that its authorization policies are enforced, and the error is logged.
 func (g *Generator) Generate(context *model.EnvoyFilterContext) ([]*model.EnvoyFilterWrapper, error) {
+	if err := model.ValidateDubboSerialization(context.ServiceEntry.Annotations); err != nil {
+		generatorLog.Errorf("service %s/%s: %v", context.ServiceEntry.Namespace, context.ServiceEntry.Name, err)
+	}
+	// the outbound proxy must not be passed to the envoyfilter generator as a typed nil, the inbound proxy is still
+	// generated so that the authorization policies are enforced
+	var outboundProxy proto.Message
+	if proxy, err := buildOutboundProxy(context); err != nil {
+		generatorLog.Errorf("failed to generate the outbound dubbo proxy of service %s/%s: %v",
+			context.ServiceEntry.Namespace, context.ServiceEntry.Name, err)
+	} else {
+		outboundProxy = proxy
+	}
 	return envoyfilter.GenerateReplaceNetworkFilter(
 		context.ServiceEntry,
 		context.ServiceEntry.Spec.Ports[0],
-		buildOutboundProxy(context),
-		buildInboundProxy(context, g.client),
+		outboundProxy,
+		buildInboundProxy(context, g.client, g.store, g.rootNamespace),
 		"envoy.filters.network.dubbo_proxy",
 		"type.googleapis.com/envoy.extensions.filters.network.dubbo_proxy.v3.DubboProxy"), nil
 }
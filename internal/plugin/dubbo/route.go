@@ -16,6 +16,8 @@ package dubbo
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	routepb "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	dubbo "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/dubbo_proxy/v3"
@@ -34,31 +36,56 @@ var (
 	regexEngine = &matcher.RegexMatcher_GoogleRe2{GoogleRe2: &matcher.RegexMatcher_GoogleRE2{}}
 )
 
-func buildOutboundRouteConfig(context *model.EnvoyFilterContext) (*dubbo.RouteConfiguration, error) {
+// buildOutboundRouteConfigs builds a RouteConfiguration for each Dubbo interface of the service.
+// The interfaces are declared in the interface annotation of the ServiceEntry, multiple interfaces exported on the
+// same port are separated by commas.
+func buildOutboundRouteConfigs(context *model.EnvoyFilterContext) ([]*dubbo.RouteConfiguration, error) {
 	// dubbo service interface should be passed in via serviceentry annotation
-	var serviceInterface string
-	var exist bool
-	if serviceInterface, exist = context.ServiceEntry.Annotations["interface"]; !exist {
+	interfaces := serviceInterfaces(context.ServiceEntry)
+	if len(interfaces) == 0 {
 		err := fmt.Errorf("no interface annotation")
 		return nil, err
 	}
 
-	var route []*dubbo.Route
 	clusterName := model.BuildClusterName(model.TrafficDirectionOutbound, "",
 		context.ServiceEntry.Spec.Hosts[0], int(context.ServiceEntry.Spec.Ports[0].Number))
 
-	if context.VirtualService == nil {
-		route = []*dubbo.Route{defaultRoute(clusterName)}
-	} else {
-		route = buildRoute(context)
+	if context.VirtualService != nil {
+		warnUnsupportedFields(context.VirtualService)
 	}
 
-	return &dubbo.RouteConfiguration{
-		Name: clusterName,
-		// To make this work, Dubbo Interface should have been registered to the Istio service registry as a service
-		Interface: serviceInterface,
-		Routes:    route,
-	}, nil
+	var routeConfigs []*dubbo.RouteConfiguration
+	for _, serviceInterface := range interfaces {
+		var route []*dubbo.Route
+		if context.VirtualService == nil {
+			route = []*dubbo.Route{defaultRoute(clusterName)}
+		} else {
+			route = buildRoute(context, serviceInterface)
+		}
+
+		name := clusterName
+		if len(interfaces) > 1 {
+			name = fmt.Sprintf("%s|%s", clusterName, serviceInterface)
+		}
+		routeConfigs = append(routeConfigs, &dubbo.RouteConfiguration{
+			Name: name,
+			// To make this work, Dubbo Interface should have been registered to the Istio service registry as a service
+			Interface: serviceInterface,
+			Routes:    route,
+		})
+	}
+	return routeConfigs, nil
+}
+
+// serviceInterfaces returns the Dubbo interfaces declared in the interface annotation of a ServiceEntry
+func serviceInterfaces(service *model.ServiceEntryWrapper) []string {
+	var interfaces []string
+	for _, serviceInterface := range strings.Split(service.Annotations["interface"], ",") {
+		if serviceInterface = strings.TrimSpace(serviceInterface); serviceInterface != "" {
+			interfaces = append(interfaces, serviceInterface)
+		}
+	}
+	return interfaces
 }
 
 func buildInboundRouteConfig(context *model.EnvoyFilterContext) *dubbo.RouteConfiguration {
@@ -94,36 +121,88 @@ func defaultRoute(clusterName string) *dubbo.Route {
 	}
 }
 
-func buildRoute(context *model.EnvoyFilterContext) []*dubbo.Route {
-	service := context.ServiceEntry.Spec
+// buildRoute builds the routes of a Dubbo interface from the VirtualService. Each match block of a http route is
+// translated into a separate Dubbo route, the uri of a match block is used to select the interfaces which it applies to,
+// a match block without uri match applies to all the interfaces.
+func buildRoute(context *model.EnvoyFilterContext, serviceInterface string) []*dubbo.Route {
 	vs := context.VirtualService.Spec
 
 	routes := make([]*dubbo.Route, 0)
 	for _, http := range vs.Http {
+		if len(http.Route) == 0 {
+			continue
+		}
 		var routeAction *dubbo.RouteAction
-
 		if len(http.Route) > 1 {
-			routeAction = buildWeightedCluster(http, service)
+			routeAction = buildWeightedCluster(http, context)
 		} else {
-			routeAction = buildSingleCluster(http, service)
+			routeAction = buildSingleCluster(http, context)
 		}
 
-		dubboRoute := &dubbo.Route{
-			Match: &dubbo.RouteMatch{
-				Method:  buildMethodMatch(http),
-				Headers: buildHeaderMatch(http),
-			},
-			Route: routeAction,
+		matches := http.Match
+		if len(matches) == 0 {
+			matches = []*networking.HTTPMatchRequest{nil}
+		}
+		for _, match := range matches {
+			if !matchInterface(match, serviceInterface) {
+				continue
+			}
+			routes = append(routes, &dubbo.Route{
+				Match: &dubbo.RouteMatch{
+					Method:  buildMethodMatch(match),
+					Headers: buildHeaderMatch(match),
+				},
+				Route: routeAction,
+			})
 		}
-		routes = append(routes, dubboRoute)
 	}
 	return routes
 }
 
-func buildMethodMatch(route *networking.HTTPRoute) *dubbo.MethodMatch {
+// warnUnsupportedFields logs a warning for each field of the VirtualService which can't be applied to Dubbo traffic,
+// these fields are ignored and the routes are still generated
+func warnUnsupportedFields(vs *model.VirtualServiceWrapper) {
+	for _, http := range vs.Spec.Http {
+		var unsupported []string
+		if http.Fault != nil {
+			unsupported = append(unsupported, "fault")
+		}
+		if http.Timeout != nil {
+			unsupported = append(unsupported, "timeout")
+		}
+		if http.Retries != nil {
+			unsupported = append(unsupported, "retries")
+		}
+		if http.Rewrite != nil {
+			unsupported = append(unsupported, "rewrite")
+		}
+		for _, field := range unsupported {
+			generatorLog.Warnf("%s of http route %q in virtual service %s/%s is not supported by dubbo, ignored",
+				field, http.Name, vs.Namespace, vs.Name)
+		}
+	}
+}
+
+func matchInterface(match *networking.HTTPMatchRequest, serviceInterface string) bool {
+	if match == nil || match.Uri == nil {
+		return true
+	}
+	switch match.Uri.MatchType.(type) {
+	case *networking.StringMatch_Exact:
+		return match.Uri.GetExact() == serviceInterface
+	case *networking.StringMatch_Prefix:
+		return strings.HasPrefix(serviceInterface, match.Uri.GetPrefix())
+	case *networking.StringMatch_Regex:
+		matched, err := regexp.MatchString(match.Uri.GetRegex(), serviceInterface)
+		return err == nil && matched
+	}
+	return false
+}
+
+func buildMethodMatch(match *networking.HTTPMatchRequest) *dubbo.MethodMatch {
 	var methodName *matcher.StringMatcher
-	if len(route.Match) > 0 {
-		method := route.Match[0].Method
+	if match != nil {
+		method := match.Method
 		if method != nil {
 			switch method.MatchType.(type) {
 			case *networking.StringMatch_Exact:
@@ -167,10 +246,10 @@ func buildMethodMatch(route *networking.HTTPRoute) *dubbo.MethodMatch {
 	}
 }
 
-func buildHeaderMatch(route *networking.HTTPRoute) []*routepb.HeaderMatcher {
+func buildHeaderMatch(match *networking.HTTPMatchRequest) []*routepb.HeaderMatcher {
 	headerMatchers := make([]*routepb.HeaderMatcher, 0)
-	if len(route.Match) > 0 {
-		for name, value := range route.Match[0].Headers {
+	if match != nil {
+		for name, value := range match.Headers {
 			switch value.MatchType.(type) {
 			case *networking.StringMatch_Exact:
 				headerMatchers = append(headerMatchers, &routepb.HeaderMatcher{
@@ -201,9 +280,39 @@ func buildHeaderMatch(route *networking.HTTPRoute) []*routepb.HeaderMatcher {
 	return headerMatchers
 }
 
-func buildSingleCluster(http *networking.HTTPRoute, service *networking.ServiceEntry) *dubbo.RouteAction {
-	clusterName := model.BuildClusterName(model.TrafficDirectionOutbound, http.Route[0].Destination.Subset,
-		service.Hosts[0], int(service.Ports[0].Number))
+// buildDestinationClusterName returns the outbound cluster of a route destination, the host and port of the service are
+// used if they are not specified in the destination
+func buildDestinationClusterName(destination *networking.Destination, context *model.EnvoyFilterContext) string {
+	service := context.ServiceEntry.Spec
+	host := resolveDestinationHost(destination.Host, context)
+	port := service.Ports[0].Number
+	if destination.Port != nil && destination.Port.Number != 0 {
+		port = destination.Port.Number
+	}
+	return model.BuildClusterName(model.TrafficDirectionOutbound, destination.Subset, host, int(port))
+}
+
+// resolveDestinationHost returns the host of the ServiceEntry which the clusters are named after if the destination host
+// is one of the hosts of the service, a short name is resolved in the namespace of the VirtualService.
+func resolveDestinationHost(host string, context *model.EnvoyFilterContext) string {
+	service := context.ServiceEntry.Spec
+	if host == "" {
+		return service.Hosts[0]
+	}
+	namespace := context.VirtualService.Namespace
+	for _, serviceHost := range service.Hosts {
+		if model.IsFQDNEquals(host, namespace, serviceHost, context.ServiceEntry.Namespace) {
+			return service.Hosts[0]
+		}
+	}
+	if !strings.Contains(host, ".") {
+		return host + "." + namespace + ".svc.cluster.local"
+	}
+	return host
+}
+
+func buildSingleCluster(http *networking.HTTPRoute, context *model.EnvoyFilterContext) *dubbo.RouteAction {
+	clusterName := buildDestinationClusterName(http.Route[0].Destination, context)
 	return &dubbo.RouteAction{
 		ClusterSpecifier: &dubbo.RouteAction_Cluster{
 			Cluster: clusterName,
@@ -211,13 +320,12 @@ func buildSingleCluster(http *networking.HTTPRoute, service *networking.ServiceE
 	}
 }
 
-func buildWeightedCluster(http *networking.HTTPRoute, service *networking.ServiceEntry) *dubbo.RouteAction {
+func buildWeightedCluster(http *networking.HTTPRoute, context *model.EnvoyFilterContext) *dubbo.RouteAction {
 	var clusterWeights []*routepb.WeightedCluster_ClusterWeight
 	var totalWeight uint32
 
 	for _, route := range http.Route {
-		clusterName := model.BuildClusterName(model.TrafficDirectionOutbound, route.Destination.Subset,
-			service.Hosts[0], int(service.Ports[0].Number))
+		clusterName := buildDestinationClusterName(route.Destination, context)
 		clusterWeight := &routepb.WeightedCluster_ClusterWeight{
 			Name:   clusterName,
 			Weight: &wrappers.UInt32Value{Value: uint32(route.Weight)}, //nolint:gosec
@@ -0,0 +1,187 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dubbo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/types/known/durationpb"
+	networking "istio.io/api/networking/v1alpha3"
+	istioconfig "istio.io/istio/pkg/config"
+
+	"github.com/aeraki-mesh/aeraki/internal/model"
+)
+
+func TestBuildRoute(t *testing.T) {
+	service := &networking.ServiceEntry{
+		Hosts: []string{"org.apache.dubbo.samples.basic.api.demoservice", "demoservice.dubbo.svc.cluster.local"},
+		Ports: []*networking.ServicePort{{Number: 20880, Name: "tcp-dubbo"}},
+	}
+	cases := []struct {
+		name             string
+		http             []*networking.HTTPRoute
+		serviceInterface string
+		wantMethods      []string
+		wantClusters     []string
+	}{
+		{
+			name: "multiple match blocks",
+			http: []*networking.HTTPRoute{{
+				Match: []*networking.HTTPMatchRequest{
+					{Method: &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: "sayHello"}}},
+					{Method: &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: "sayHi"}}},
+				},
+				Route: []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Subset: "v1"}}},
+			}},
+			serviceInterface: "org.apache.dubbo.samples.basic.api.DemoService",
+			wantMethods:      []string{"sayHello", "sayHi"},
+			wantClusters: []string{
+				"outbound|20880|v1|org.apache.dubbo.samples.basic.api.demoservice",
+				"outbound|20880|v1|org.apache.dubbo.samples.basic.api.demoservice",
+			},
+		},
+		{
+			name: "match blocks of other interfaces",
+			http: []*networking.HTTPRoute{{
+				Match: []*networking.HTTPMatchRequest{
+					{
+						Uri:    &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: "Other"}},
+						Method: &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: "sayHello"}},
+					},
+					{
+						Uri:    &networking.StringMatch{MatchType: &networking.StringMatch_Prefix{Prefix: "org.apache"}},
+						Method: &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: "sayHi"}},
+					},
+				},
+				Route: []*networking.HTTPRouteDestination{{Destination: &networking.Destination{}}},
+			}},
+			serviceInterface: "org.apache.dubbo.samples.basic.api.DemoService",
+			wantMethods:      []string{"sayHi"},
+			wantClusters:     []string{"outbound|20880||org.apache.dubbo.samples.basic.api.demoservice"},
+		},
+		{
+			name: "destination host and port",
+			http: []*networking.HTTPRoute{{
+				Match: []*networking.HTTPMatchRequest{
+					{Method: &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: "sayHello"}}},
+				},
+				Route: []*networking.HTTPRouteDestination{{Destination: &networking.Destination{
+					Host: "org.apache.dubbo.samples.basic.api.demoservice-canary",
+					Port: &networking.PortSelector{Number: 20881},
+				}}},
+			}},
+			serviceInterface: "org.apache.dubbo.samples.basic.api.DemoService",
+			wantMethods:      []string{"sayHello"},
+			wantClusters:     []string{"outbound|20881||org.apache.dubbo.samples.basic.api.demoservice-canary"},
+		},
+		{
+			name: "destination hosts of the service",
+			http: []*networking.HTTPRoute{{
+				Match: []*networking.HTTPMatchRequest{
+					{Method: &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: "sayHello"}}},
+				},
+				Route: []*networking.HTTPRouteDestination{
+					{Destination: &networking.Destination{Host: "demoservice", Subset: "v1"}, Weight: 50},
+					{Destination: &networking.Destination{Host: "demoservice.dubbo.svc.cluster.local", Subset: "v2"},
+						Weight: 50},
+				},
+			}},
+			serviceInterface: "org.apache.dubbo.samples.basic.api.DemoService",
+			wantMethods:      []string{"sayHello"},
+			wantClusters: []string{
+				"outbound|20880|v1|org.apache.dubbo.samples.basic.api.demoservice",
+				"outbound|20880|v2|org.apache.dubbo.samples.basic.api.demoservice",
+			},
+		},
+		{
+			name: "short name of another service",
+			http: []*networking.HTTPRoute{{
+				Route: []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: "canary"}}},
+			}},
+			serviceInterface: "org.apache.dubbo.samples.basic.api.DemoService",
+			wantMethods:      []string{""},
+			wantClusters:     []string{"outbound|20880||canary.dubbo.svc.cluster.local"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			context := &model.EnvoyFilterContext{
+				ServiceEntry: &model.ServiceEntryWrapper{
+					Meta: istioconfig.Meta{Namespace: "dubbo"},
+					Spec: service,
+				},
+				VirtualService: &model.VirtualServiceWrapper{
+					Meta: istioconfig.Meta{Namespace: "dubbo"},
+					Spec: &networking.VirtualService{Http: c.http},
+				},
+			}
+			var gotMethods, gotClusters []string
+			for _, route := range buildRoute(context, c.serviceInterface) {
+				gotMethods = append(gotMethods, route.Match.Method.Name.GetExact())
+				if weighted := route.Route.GetWeightedClusters(); weighted != nil {
+					for _, cluster := range weighted.Clusters {
+						gotClusters = append(gotClusters, cluster.Name)
+					}
+				} else {
+					gotClusters = append(gotClusters, route.Route.GetCluster())
+				}
+			}
+			if diff := cmp.Diff(c.wantMethods, gotMethods); diff != "" {
+				t.Errorf("unexpected methods (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(c.wantClusters, gotClusters); diff != "" {
+				t.Errorf("unexpected clusters (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBuildOutboundRouteConfigsUnsupportedFields(t *testing.T) {
+	context := &model.EnvoyFilterContext{
+		ServiceEntry: &model.ServiceEntryWrapper{
+			Meta: istioconfig.Meta{
+				Namespace:   "dubbo",
+				Annotations: map[string]string{"interface": "org.apache.dubbo.samples.basic.api.DemoService"},
+			},
+			Spec: &networking.ServiceEntry{
+				Hosts: []string{"org.apache.dubbo.samples.basic.api.demoservice"},
+				Ports: []*networking.ServicePort{{Number: 20880, Name: "tcp-dubbo"}},
+			},
+		},
+		VirtualService: &model.VirtualServiceWrapper{
+			Meta: istioconfig.Meta{Namespace: "dubbo"},
+			Spec: &networking.VirtualService{Http: []*networking.HTTPRoute{{
+				Timeout: durationpb.New(time.Second),
+				Retries: &networking.HTTPRetry{Attempts: 3},
+				Route:   []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Subset: "v1"}}},
+			}}},
+		},
+	}
+	// the timeout and retries are ignored, the routes are still generated
+	configs, err := buildOutboundRouteConfigs(context)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(configs) != 1 || len(configs[0].Routes) != 1 {
+		t.Fatalf("want 1 route, got %v", configs)
+	}
+	if got, want := configs[0].Routes[0].Route.GetCluster(),
+		"outbound|20880|v1|org.apache.dubbo.samples.basic.api.demoservice"; got != want {
+		t.Errorf("want cluster %s, got %s", want, got)
+	}
+}
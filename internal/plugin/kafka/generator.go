@@ -15,21 +15,47 @@
 package kafka
 
 import (
+	"istio.io/pkg/log"
+
 	"github.com/aeraki-mesh/aeraki/internal/envoyfilter"
 	"github.com/aeraki-mesh/aeraki/internal/model"
 )
 
+var generatorLog = log.RegisterScope("kafka-generator", "kafka generator", 0)
+
 // Generator defines a kafka envoyfilter Generator
 type Generator struct {
+	enableMesh bool
 }
 
-// NewGenerator creates an new kafka Generator instance
-func NewGenerator() *Generator {
-	return &Generator{}
+// NewGenerator creates an new kafka Generator instance, the kafka_mesh filter is generated only if enableMesh is
+// true because it requires the contrib images of Envoy on all the sidecars.
+func NewGenerator(enableMesh bool) *Generator {
+	return &Generator{
+		enableMesh: enableMesh,
+	}
 }
 
-// Generate create EnvoyFilters for Dubbo services
-func (*Generator) Generate(context *model.EnvoyFilterContext) ([]*model.EnvoyFilterWrapper, error) {
+// Generate create EnvoyFilters for Kafka services
+// The kafka_broker filter is inserted for the stats of a Kafka service, while a Kafka mesh service replaces the tcp
+// proxy of its outbound listener with the kafka_mesh filter.
+func (g *Generator) Generate(context *model.EnvoyFilterContext) ([]*model.EnvoyFilterWrapper, error) {
+	if _, ok := context.ServiceEntry.Annotations[meshAnnotation]; ok && !g.enableMesh {
+		generatorLog.Warnf("kafka mesh is disabled, ignore the %s annotation of %s/%s", meshAnnotation,
+			context.ServiceEntry.Namespace, context.ServiceEntry.Name)
+	} else if ok {
+		meshConfig, err := parseMesh(context.ServiceEntry)
+		if err != nil {
+			return nil, err
+		}
+		return envoyfilter.GenerateReplaceNetworkFilter(
+			context.ServiceEntry,
+			context.ServiceEntry.Spec.Ports[0],
+			meshConfig,
+			nil,
+			"envoy.filters.network.kafka_mesh",
+			"type.googleapis.com/envoy.extensions.filters.network.kafka_mesh.v3alpha.KafkaMesh"), nil
+	}
 	return envoyfilter.GenerateInsertBeforeNetworkFilter(
 		context.ServiceEntry,
 		buildOutboundProxy(context),
@@ -0,0 +1,98 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	networking "istio.io/api/networking/v1alpha3"
+	istioconfig "istio.io/istio/pkg/config"
+
+	"github.com/aeraki-mesh/aeraki/internal/model"
+)
+
+const testMesh = `{
+	"upstream_clusters": [{"cluster_name": "c1", "bootstrap_servers": "kafka1:9092", "partition_count": 1}],
+	"forwarding_rules": [{"target_cluster": "c1", "topic_prefix": "apples"}]
+}`
+
+func TestGenerate(t *testing.T) {
+	cases := []struct {
+		name        string
+		enableMesh  bool
+		annotations map[string]string
+		wantFilter  string
+		wantOp      networking.EnvoyFilter_Patch_Operation
+		wantErr     bool
+	}{
+		{
+			name:       "kafka broker",
+			enableMesh: true,
+			wantFilter: "envoy.filters.network.kafka_broker",
+			wantOp:     networking.EnvoyFilter_Patch_INSERT_BEFORE,
+		},
+		{
+			name:        "kafka mesh",
+			enableMesh:  true,
+			annotations: map[string]string{meshAnnotation: testMesh},
+			wantFilter:  "envoy.filters.network.kafka_mesh",
+			wantOp:      networking.EnvoyFilter_Patch_REPLACE,
+		},
+		{
+			name:        "kafka mesh disabled",
+			annotations: map[string]string{meshAnnotation: testMesh},
+			wantFilter:  "envoy.filters.network.kafka_broker",
+			wantOp:      networking.EnvoyFilter_Patch_INSERT_BEFORE,
+		},
+		{
+			name:        "invalid kafka mesh",
+			enableMesh:  true,
+			annotations: map[string]string{meshAnnotation: `{"forwarding_rules": [{"target_cluster": "c1"}]}`},
+			wantErr:     true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			context := &model.EnvoyFilterContext{
+				ServiceEntry: &model.ServiceEntryWrapper{
+					Meta: istioconfig.Meta{Name: "kafka", Namespace: "mesh", Annotations: c.annotations},
+					Spec: &networking.ServiceEntry{
+						Hosts:     []string{"kafka.mesh.svc.cluster.local"},
+						Addresses: []string{"10.0.0.1"},
+						Ports:     []*networking.ServicePort{{Number: 9092, Name: "tcp-kafka"}},
+					},
+				},
+			}
+			filters, err := NewGenerator(c.enableMesh).Generate(context)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.wantErr {
+				return
+			}
+			if len(filters) != 1 {
+				t.Fatalf("want 1 outbound EnvoyFilter, got %d", len(filters))
+			}
+			patch := filters[0].Envoyfilter.ConfigPatches[0].Patch
+			if patch.Operation != c.wantOp {
+				t.Errorf("want %v operation, got %v", c.wantOp, patch.Operation)
+			}
+			if got := patch.Value.GetFields()["name"].GetStringValue(); got != c.wantFilter {
+				t.Errorf("want %s filter, got %s", c.wantFilter, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+
+	mesh "github.com/envoyproxy/go-control-plane/contrib/envoy/extensions/filters/network/kafka_mesh/v3alpha"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/aeraki-mesh/aeraki/internal/model"
+)
+
+// meshAnnotation specifies the JSON config of the kafka_mesh filter, which serves the clients as a single Kafka
+// broker and forwards the requests to several upstream Kafka clusters by the topic prefixes.
+// The advertised host and port default to the host and port of the service.
+// Note that the kafka_mesh filter is only available in the contrib images of Envoy, so it's generated only if the
+// Kafka mesh is enabled.
+const meshAnnotation = "kafka.aeraki.io/mesh"
+
+// ValidateAnnotations validates the kafka_mesh config in the annotations of a ServiceEntry
+func ValidateAnnotations(annotations map[string]string) error {
+	value, ok := annotations[meshAnnotation]
+	if !ok {
+		return nil
+	}
+	_, err := unmarshalMesh(value)
+	return err
+}
+
+// parseMesh parses the kafka_mesh config of a service from its annotations, nil is returned if the service isn't a
+// Kafka mesh
+func parseMesh(service *model.ServiceEntryWrapper) (*mesh.KafkaMesh, error) {
+	value, ok := service.Annotations[meshAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	config, err := unmarshalMesh(value)
+	if err != nil {
+		return nil, err
+	}
+	if config.AdvertisedHost == "" {
+		config.AdvertisedHost = service.Spec.Hosts[0]
+	}
+	if config.AdvertisedPort == 0 {
+		config.AdvertisedPort = int32(service.Spec.Ports[0].Number) //nolint:gosec
+	}
+	if err := config.ValidateAll(); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", meshAnnotation, err)
+	}
+	return config, nil
+}
+
+// unmarshalMesh unmarshals and validates the kafka_mesh config, the advertised host and port may be left empty
+func unmarshalMesh(value string) (*mesh.KafkaMesh, error) {
+	config := &mesh.KafkaMesh{}
+	if err := protojson.Unmarshal([]byte(value), config); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", meshAnnotation, err)
+	}
+	if err := validateMesh(config); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", meshAnnotation, err)
+	}
+	return config, nil
+}
+
+func validateMesh(config *mesh.KafkaMesh) error {
+	if config.AdvertisedPort < 0 {
+		return fmt.Errorf("invalid advertised port: %d", config.AdvertisedPort)
+	}
+	clusters := make(map[string]bool)
+	for _, cluster := range config.UpstreamClusters {
+		if err := cluster.ValidateAll(); err != nil {
+			return err
+		}
+		if clusters[cluster.ClusterName] {
+			return fmt.Errorf("duplicate upstream cluster: %s", cluster.ClusterName)
+		}
+		clusters[cluster.ClusterName] = true
+	}
+	for _, rule := range config.ForwardingRules {
+		if err := rule.ValidateAll(); err != nil {
+			return err
+		}
+		if !clusters[rule.TargetCluster] {
+			return fmt.Errorf("target cluster %s of topic prefix %s is not defined", rule.TargetCluster,
+				rule.GetTopicPrefix())
+		}
+	}
+	return nil
+}
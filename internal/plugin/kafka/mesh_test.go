@@ -0,0 +1,142 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	mesh "github.com/envoyproxy/go-control-plane/contrib/envoy/extensions/filters/network/kafka_mesh/v3alpha"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	networking "istio.io/api/networking/v1alpha3"
+	istioconfig "istio.io/istio/pkg/config"
+
+	"github.com/aeraki-mesh/aeraki/internal/model"
+)
+
+func TestParseMesh(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation string
+		want       *mesh.KafkaMesh
+		wantErr    bool
+	}{
+		{
+			name: "topic prefix routing",
+			annotation: `{
+				"upstream_clusters": [
+					{"cluster_name": "c1", "bootstrap_servers": "kafka1:9092", "partition_count": 1,
+						"producer_config": {"acks": "1"}},
+					{"cluster_name": "c2", "bootstrap_servers": "kafka2:9092", "partition_count": 3}
+				],
+				"forwarding_rules": [
+					{"target_cluster": "c1", "topic_prefix": "apples"},
+					{"target_cluster": "c2", "topic_prefix": "bananas"}
+				]
+			}`,
+			want: &mesh.KafkaMesh{
+				AdvertisedHost: "kafka.mesh.svc.cluster.local",
+				AdvertisedPort: 9092,
+				UpstreamClusters: []*mesh.KafkaClusterDefinition{
+					{
+						ClusterName:      "c1",
+						BootstrapServers: "kafka1:9092",
+						PartitionCount:   1,
+						ProducerConfig:   map[string]string{"acks": "1"},
+					},
+					{ClusterName: "c2", BootstrapServers: "kafka2:9092", PartitionCount: 3},
+				},
+				ForwardingRules: []*mesh.ForwardingRule{
+					{TargetCluster: "c1", Trigger: &mesh.ForwardingRule_TopicPrefix{TopicPrefix: "apples"}},
+					{TargetCluster: "c2", Trigger: &mesh.ForwardingRule_TopicPrefix{TopicPrefix: "bananas"}},
+				},
+			},
+		},
+		{
+			name: "undefined target cluster",
+			annotation: `{
+				"upstream_clusters": [{"cluster_name": "c1", "bootstrap_servers": "kafka1:9092", "partition_count": 1}],
+				"forwarding_rules": [{"target_cluster": "c2", "topic_prefix": "apples"}]
+			}`,
+			wantErr: true,
+		},
+		{
+			name: "invalid partition count",
+			annotation: `{
+				"upstream_clusters": [{"cluster_name": "c1", "bootstrap_servers": "kafka1:9092"}]
+			}`,
+			wantErr: true,
+		},
+		{
+			name:       "invalid json",
+			annotation: `{"upstream_clusters": `,
+			wantErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			service := &model.ServiceEntryWrapper{
+				Meta: istioconfig.Meta{Annotations: map[string]string{meshAnnotation: c.annotation}},
+				Spec: &networking.ServiceEntry{
+					Hosts: []string{"kafka.mesh.svc.cluster.local"},
+					Ports: []*networking.ServicePort{{Number: 9092, Name: "tcp-kafka"}},
+				},
+			}
+			got, err := parseMesh(service)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(c.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected config (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateAnnotations(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		wantErr     bool
+	}{
+		{name: "no kafka mesh"},
+		{
+			// the advertised host and port are left to the defaults of the service
+			name:        "kafka mesh",
+			annotations: map[string]string{meshAnnotation: testMesh},
+		},
+		{
+			name: "undefined target cluster",
+			annotations: map[string]string{meshAnnotation: `{
+				"forwarding_rules": [{"target_cluster": "c2", "topic_prefix": "apples"}]
+			}`},
+			wantErr: true,
+		},
+		{
+			name:        "invalid advertised port",
+			annotations: map[string]string{meshAnnotation: `{"advertised_port": -1}`},
+			wantErr:     true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := ValidateAnnotations(c.annotations); (err != nil) != c.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -20,8 +20,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aeraki-mesh/client-go/pkg/clientset/versioned"
-	redisv1alpha1 "github.com/aeraki-mesh/client-go/pkg/clientset/versioned/typed/redis/v1alpha1"
 	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	_struct "github.com/golang/protobuf/ptypes/struct"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -29,9 +27,7 @@ import (
 	networking "istio.io/api/networking/v1alpha3"
 	istiomodel "istio.io/istio/pilot/pkg/model"
 	"istio.io/pkg/log"
-	"k8s.io/client-go/kubernetes"
-	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
-	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/aeraki-mesh/aeraki/internal/envoyfilter"
 	"github.com/aeraki-mesh/aeraki/internal/model"
@@ -40,21 +36,14 @@ import (
 var generatorLog = log.RegisterScope("redis-generator", "redis generator", 0)
 
 // New Generator
-func New(cfg *rest.Config, store istiomodel.ConfigStore) *Generator {
-	clientset, err := versioned.NewForConfig(cfg)
-	if err != nil {
-		generatorLog.Fatalf("Could not create clientset: %e", err)
-	}
-
-	k8scli, err := kubernetes.NewForConfig(cfg)
-	if err != nil {
-		generatorLog.Fatalf("Could not create clientset: %e", err)
-	}
-
+// The client is expected to be backed by the informer cache of a controller manager, with the host field indexes.
+// The secrets of redis auth are read through the secretReader, which should read from the API server directly, so
+// the secrets in the cluster don't need to be cached.
+func New(c client.Client, secretReader client.Reader, store istiomodel.ConfigStore) *Generator {
 	g := &Generator{
-		secretsGetter: k8scli.CoreV1(),
-		redis:         clientset.RedisV1alpha1(),
-		store:         store,
+		client:       c,
+		secretReader: secretReader,
+		store:        store,
 	}
 	generatorLog.Infof("redis generator created")
 	return g
@@ -62,9 +51,9 @@ func New(cfg *rest.Config, store istiomodel.ConfigStore) *Generator {
 
 // Generator generate redis proxy filter configuration for redis service
 type Generator struct {
-	secretsGetter corev1.SecretsGetter
-	redis         redisv1alpha1.RedisV1alpha1Interface
-	store         istiomodel.ConfigStore
+	client       client.Client
+	secretReader client.Reader
+	store        istiomodel.ConfigStore
 }
 
 // Timeout is the default timeout for listing object from apiserver
@@ -77,33 +66,65 @@ func (g *Generator) Generate(filterContext *model.EnvoyFilterContext) (filters [
 	se := filterContext.ServiceEntry.Spec
 	for _, port := range se.Ports {
 		if strings.HasPrefix(port.Name, "tcp-redis") {
-			portFilters := g.generate(ctx, filterContext, port)
-			if portFilters != nil {
-				filters = append(filters, portFilters...)
+			portFilters, err := g.generate(ctx, filterContext, port)
+			if err != nil {
+				return nil, err
 			}
+			filters = append(filters, portFilters...)
 		}
 	}
 	return filters, nil
 }
 
 func (g *Generator) generate(ctx context.Context, filterContext *model.EnvoyFilterContext,
-	targetPort *networking.ServicePort) []*model.EnvoyFilterWrapper {
+	targetPort *networking.ServicePort) ([]*model.EnvoyFilterWrapper, error) {
 	port := targetPort.Number
 	portName := targetPort.Name
 	generatorLog.Debugf("generate %s/%s/%s", filterContext.ServiceEntry.Namespace,
 		filterContext.ServiceEntry.Name, portName)
-	// copy and replace ports
-	spec := *filterContext.ServiceEntry.Spec.DeepCopy()
-	spec.Ports = []*networking.ServicePort{targetPort}
+	destination, err := g.findRedisDestination(ctx, filterContext.ServiceEntry.Namespace,
+		filterContext.ServiceEntry.Spec.Hosts)
+	if err != nil {
+		generatorLog.Errorf("could not list RedisDestinations: %e", err)
+	}
+	// the inbound proxy and patches are skipped if the auth can't be read, the inbound proxy must not be passed to the
+	// envoyfilter generator as a typed nil, otherwise an empty redis proxy will be generated
+	var inboundProxy proto.Message
+	var inboundPatches []*networking.EnvoyFilter_EnvoyConfigObjectPatch
+	auth, err := g.buildInboundAuth(filterContext.ServiceEntry.Namespace, destinationSpec(destination))
+	if err != nil {
+		generatorLog.Errorf("get password from auth: %e", err)
+	} else {
+		inboundProxy = g.buildInboundProxy(ctx, filterContext, port, auth)
+		inboundPatches = g.buildInboundPatches(port, destinationSpec(destination), auth)
+	}
+	outboundProxy, err := g.buildOutboundProxyWithFallback(ctx, filterContext, port, portName)
+	if err != nil {
+		return nil, err
+	}
 	filters := envoyfilter.GenerateReplaceNetworkFilter(
 		filterContext.ServiceEntry,
-		filterContext.ServiceEntry.Spec.Ports[0],
-		g.buildOutboundProxyWithFallback(ctx, filterContext, port, portName),
-		g.buildInboundProxy(filterContext),
+		targetPort,
+		outboundProxy,
+		inboundProxy,
 		"envoy.filters.network.redis_proxy",
 		"type.googleapis.com/envoy.extensions.filters.network.redis_proxy.v3.RedisProxy")
 
+	// the inbound EnvoyFilters are scoped by the workload selector of the service
+	for _, filter := range filters {
+		if filter.Envoyfilter.WorkloadSelector != nil {
+			filter.Envoyfilter.ConfigPatches = append(filter.Envoyfilter.ConfigPatches, inboundPatches...)
+		}
+	}
+
 	cluster := g.buildOutboundCluster(ctx, filterContext, port)
+	if cluster != nil {
+		// the cluster is skipped rather than downgraded to plaintext, Istio's cluster of the service is used instead
+		if err := g.setUpstreamTLS(cluster, filterContext.ServiceEntry, port); err != nil {
+			generatorLog.Errorf("skip redis cluster %s, invalid upstream tls: %v", cluster.Name, err)
+			cluster = nil
+		}
+	}
 	if cluster != nil {
 		for _, filter := range filters {
 			if filter.Envoyfilter.WorkloadSelector == nil {
@@ -116,7 +137,7 @@ func (g *Generator) generate(ctx context.Context, filterContext *model.EnvoyFilt
 		fdata, _ := json.Marshal(filters)
 		generatorLog.Infof("%s", string(fdata))
 	}
-	return filters
+	return filters, nil
 }
 
 // ReplaceClusterPatches create a `replace` operation patch on `cluster`
@@ -0,0 +1,147 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	spec "github.com/aeraki-mesh/api/redis/v1alpha1"
+	"github.com/aeraki-mesh/client-go/pkg/apis/redis/v1alpha1"
+	aerakischeme "github.com/aeraki-mesh/client-go/pkg/clientset/versioned/scheme"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/config/memory"
+	istioconfig "istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/collections"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/aeraki-mesh/aeraki/internal/config/constants"
+	"github.com/aeraki-mesh/aeraki/internal/model"
+)
+
+const testHost = "redis.redis.svc.cluster.local"
+
+// newTestGenerator creates a Generator backed by a fake client with the host indexes
+func newTestGenerator(t *testing.T, objects ...client.Object) *Generator {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := aerakischeme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+		WithIndex(&v1alpha1.RedisService{}, constants.HostIndex, func(obj client.Object) []string {
+			return obj.(*v1alpha1.RedisService).Spec.Host
+		}).
+		WithIndex(&v1alpha1.RedisDestination{}, constants.HostIndex, func(obj client.Object) []string {
+			return []string{obj.(*v1alpha1.RedisDestination).Spec.Host}
+		}).
+		Build()
+	return New(c, c, memory.MakeSkipValidation(collections.Pilot))
+}
+
+func testServiceEntry() *model.EnvoyFilterContext {
+	return &model.EnvoyFilterContext{
+		ServiceEntry: &model.ServiceEntryWrapper{
+			Meta: istioconfig.Meta{Name: "redis", Namespace: "redis"},
+			Spec: &networking.ServiceEntry{
+				Hosts:     []string{testHost},
+				Addresses: []string{"10.0.0.1"},
+				Ports:     []*networking.ServicePort{{Number: 6379, Name: "tcp-redis"}},
+				WorkloadSelector: &networking.WorkloadSelector{
+					Labels: map[string]string{"app": "redis"},
+				},
+			},
+		},
+	}
+}
+
+func TestGenerateInboundAuth(t *testing.T) {
+	destination := &v1alpha1.RedisDestination{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "redis"},
+		Spec: spec.RedisDestination{
+			Host: testHost,
+			TrafficPolicy: &spec.TrafficPolicy{
+				ConnectionPool: &spec.ConnectionPoolSettings{
+					Redis: &spec.RedisSettings{
+						Auth: &spec.Auth{
+							Auth: &spec.Auth_Secret{Secret: &spec.SecretReference{Name: "redis-auth"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis-auth", Namespace: "redis"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+
+	cases := []struct {
+		name        string
+		objects     []client.Object
+		wantInbound bool
+	}{
+		{
+			name:        "secret found",
+			objects:     []client.Object{destination.DeepCopy(), secret},
+			wantInbound: true,
+		},
+		{
+			// no inbound redis proxy should be generated, an empty one would be rejected by Envoy
+			name:    "secret missing",
+			objects: []client.Object{destination.DeepCopy()},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			filters, err := newTestGenerator(t, c.objects...).Generate(testServiceEntry())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var inbound []*model.EnvoyFilterWrapper
+			for _, filter := range filters {
+				if filter.Envoyfilter.WorkloadSelector != nil {
+					inbound = append(inbound, filter)
+				}
+			}
+			if !c.wantInbound {
+				if len(inbound) != 0 {
+					t.Errorf("unexpected inbound EnvoyFilters: %v", inbound)
+				}
+				return
+			}
+			if len(inbound) != 1 {
+				t.Fatalf("want 1 inbound EnvoyFilter, got %d", len(inbound))
+			}
+			data, err := json.Marshal(inbound[0].Envoyfilter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), `"downstreamAuthPassword":{"inlineString":"secret"}`) {
+				t.Errorf("downstream auth password not found in inbound EnvoyFilter: %s", data)
+			}
+		})
+	}
+}
@@ -15,13 +15,47 @@
 package redis
 
 import (
+	"context"
+
+	spec "github.com/aeraki-mesh/api/redis/v1alpha1"
+	"github.com/aeraki-mesh/client-go/pkg/apis/redis/v1alpha1"
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	connectionlimit "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/connection_limit/v3"
 	redis "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/redis_proxy/v3"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"google.golang.org/protobuf/types/known/anypb"
+	networking "istio.io/api/networking/v1alpha3"
 
 	"github.com/aeraki-mesh/aeraki/internal/model"
 )
 
-func (g *Generator) buildInboundProxy(context *model.EnvoyFilterContext) *redis.RedisProxy {
-	name := model.BuildClusterName(model.TrafficDirectionInbound, "", "", int(context.ServiceEntry.Spec.Ports[0].Number))
+// inboundAuth is the auth of the RedisDestination, it's read once per generation and shared by the inbound proxy and
+// the inbound cluster, so that both of them use the same password.
+type inboundAuth struct {
+	username string
+	password string
+}
+
+// buildInboundAuth reads the auth of the RedisDestination, it returns nil if the RedisDestination has no auth.
+func (g *Generator) buildInboundAuth(namespace string, destination *spec.RedisDestination) (*inboundAuth, error) {
+	auth := destinationAuth(destination)
+	if auth == nil {
+		return nil, nil
+	}
+	username, password, err := g.password(namespace, auth)
+	if err != nil {
+		return nil, err
+	}
+	return &inboundAuth{username: username, password: password}, nil
+}
+
+// buildInboundProxy builds the inbound redis proxy of a service.
+// The op timeout and command stats follow the settings of the RedisService. The client sidecars authenticate with the
+// auth of the RedisDestination, so it's used as the downstream auth of the inbound proxy.
+func (g *Generator) buildInboundProxy(ctx context.Context, c *model.EnvoyFilterContext,
+	listenPort uint32, auth *inboundAuth) *redis.RedisProxy {
+	name := model.BuildClusterName(model.TrafficDirectionInbound, "", "", int(listenPort))
 	proxy := &redis.RedisProxy{
 		StatPrefix: name,
 		Settings: &redis.RedisProxy_ConnPoolSettings{
@@ -31,5 +65,121 @@ func (g *Generator) buildInboundProxy(context *model.EnvoyFilterContext) *redis.
 			CatchAllRoute: &redis.RedisProxy_PrefixRoutes_Route{Cluster: name},
 		},
 	}
+
+	_, rs, err := g.findTargetHostAndRedisService(ctx, c.ServiceEntry.Namespace, c.ServiceEntry.Spec.Hosts)
+	if err != nil {
+		generatorLog.Errorf("build inbound %s/%s :%e", c.ServiceEntry.Namespace, c.ServiceEntry.Name, err)
+	}
+	if rs != nil && rs.Spec.Settings != nil {
+		if rs.Spec.Settings.OpTimeout != nil {
+			proxy.Settings.OpTimeout = rs.Spec.Settings.OpTimeout
+		}
+		proxy.Settings.EnableCommandStats = rs.Spec.Settings.EnableCommandStats
+	}
+
+	if auth != nil {
+		if auth.password != "" {
+			// nolint: staticcheck
+			proxy.DownstreamAuthPassword = inlineString(auth.password)
+		}
+		if auth.username != "" {
+			proxy.DownstreamAuthUsername = inlineString(auth.username)
+		}
+	}
 	return proxy
 }
+
+// buildInboundPatches builds the patches which are applied together with the inbound redis proxy:
+// a connection_limit filter which enforces the max connections of the RedisDestination, and the auth of the inbound
+// cluster, since the inbound proxy terminates the AUTH command of the client sidecars.
+func (g *Generator) buildInboundPatches(listenPort uint32, destination *spec.RedisDestination,
+	auth *inboundAuth) []*networking.EnvoyFilter_EnvoyConfigObjectPatch {
+	var patches []*networking.EnvoyFilter_EnvoyConfigObjectPatch
+	name := model.BuildClusterName(model.TrafficDirectionInbound, "", "", int(listenPort))
+
+	if tcp := destination.GetTrafficPolicy().GetConnectionPool().GetTcp(); tcp != nil && tcp.MaxConnections > 0 {
+		connectionLimit, err := anypb.New(&connectionlimit.ConnectionLimit{
+			StatPrefix:     name,
+			MaxConnections: &wrappers.UInt64Value{Value: uint64(tcp.MaxConnections)}, //nolint:gosec
+		})
+		if err != nil {
+			generatorLog.Errorf("ConnectionLimit create failed: %e", err)
+			return nil
+		}
+		value, err := valueOf(&listener.Filter{
+			Name:       "envoy.filters.network.connection_limit",
+			ConfigType: &listener.Filter_TypedConfig{TypedConfig: connectionLimit},
+		})
+		if err != nil {
+			generatorLog.Errorf("convert connection limit filter to struct failed: %e", err)
+			return nil
+		}
+		patches = append(patches, &networking.EnvoyFilter_EnvoyConfigObjectPatch{
+			ApplyTo: networking.EnvoyFilter_NETWORK_FILTER,
+			Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+				ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+					Listener: &networking.EnvoyFilter_ListenerMatch{
+						Name: "virtualInbound",
+						FilterChain: &networking.EnvoyFilter_ListenerMatch_FilterChainMatch{
+							DestinationPort: listenPort,
+							Filter: &networking.EnvoyFilter_ListenerMatch_FilterMatch{
+								Name: "envoy.filters.network.redis_proxy",
+							},
+						},
+					},
+				},
+			},
+			Patch: &networking.EnvoyFilter_Patch{
+				Operation: networking.EnvoyFilter_Patch_INSERT_BEFORE,
+				Value:     value,
+			},
+		})
+	}
+
+	if auth != nil {
+		protocolOptions, err := anypb.New(&redis.RedisProtocolOptions{
+			AuthPassword: inlineString(auth.password),
+			AuthUsername: inlineString(auth.username),
+		})
+		if err != nil {
+			generatorLog.Errorf("RedisProtocolOptions create failed: %e", err)
+			return nil
+		}
+		value, err := valueOf(&cluster.Cluster{
+			TypedExtensionProtocolOptions: map[string]*anypb.Any{
+				"envoy.filters.network.redis_proxy": protocolOptions,
+			},
+		})
+		if err != nil {
+			generatorLog.Errorf("convert cluster to struct failed: %e", err)
+			return nil
+		}
+		patches = append(patches, &networking.EnvoyFilter_EnvoyConfigObjectPatch{
+			ApplyTo: networking.EnvoyFilter_CLUSTER,
+			Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: networking.EnvoyFilter_SIDECAR_INBOUND,
+				ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Cluster{
+					Cluster: &networking.EnvoyFilter_ClusterMatch{
+						PortNumber: listenPort,
+					},
+				},
+			},
+			Patch: &networking.EnvoyFilter_Patch{
+				Operation: networking.EnvoyFilter_Patch_MERGE,
+				Value:     value,
+			},
+		})
+	}
+	return patches
+}
+
+func destinationAuth(destination *spec.RedisDestination) *spec.Auth {
+	return destination.GetTrafficPolicy().GetConnectionPool().GetRedis().GetAuth()
+}
+
+func destinationSpec(destination *v1alpha1.RedisDestination) *spec.RedisDestination {
+	if destination == nil {
+		return nil
+	}
+	return &destination.Spec
+}
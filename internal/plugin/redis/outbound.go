@@ -16,6 +16,8 @@ package redis
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -26,9 +28,12 @@ import (
 	"google.golang.org/protobuf/types/known/durationpb"
 	networking "istio.io/api/networking/v1alpha3"
 	"istio.io/istio/pkg/config/schema/gvk"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
+	"istio.io/istio/pkg/config/visibility"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/aeraki-mesh/aeraki/internal/config/constants"
 	"github.com/aeraki-mesh/aeraki/internal/model"
 )
 
@@ -38,14 +43,23 @@ const (
 	defaultPassword2 = "token"
 )
 
+// errDownstreamAuth is returned if the downstream auth of a RedisService can't be built
+var errDownstreamAuth = errors.New("invalid downstream auth")
+
 var (
 	defaultOpTimeout        = durationpb.New(time.Minute)
 	defaultInboundOpTimeout = durationpb.New(time.Hour)
 )
 
+// buildOutboundProxyWithFallback builds the outbound proxy of a service, a default proxy is used if there's no
+// RedisService or it can't be built. An error is returned if the downstream auth can't be built, so that the proxy
+// isn't replaced by one without auth.
 func (g *Generator) buildOutboundProxyWithFallback(ctx context.Context, c *model.EnvoyFilterContext, listenPort uint32,
-	listenPortName string) *redis.RedisProxy {
+	listenPortName string) (*redis.RedisProxy, error) {
 	proxy, err := g.buildOutboundProxy(ctx, c, listenPort, listenPortName)
+	if errors.Is(err, errDownstreamAuth) {
+		return nil, err
+	}
 	if err != nil {
 		generatorLog.Errorf("build outbound %s/%s :%e", c.ServiceEntry.Namespace, c.ServiceEntry.Name, err)
 	}
@@ -61,9 +75,9 @@ func (g *Generator) buildOutboundProxyWithFallback(ctx context.Context, c *model
 					Cluster: outboundClusterName(c.ServiceEntry.Spec.Hosts[0], listenPort),
 				},
 			},
-		}
+		}, nil
 	}
-	return proxy
+	return proxy, nil
 }
 
 func (g *Generator) buildOutboundProxy(ctx context.Context, c *model.EnvoyFilterContext, listenPort uint32,
@@ -96,6 +110,13 @@ func (g *Generator) buildOutboundProxy(ctx context.Context, c *model.EnvoyFilter
 		}
 	}
 
+	if rs.Spec.Settings.GetAuth() != nil || len(model.GetRedisDownstreamAuthSecrets(rs.Annotations)) > 0 {
+		err = g.buildAuth(proxy, rs)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errDownstreamAuth, err)
+		}
+	}
+
 	if len(rs.Spec.Redis) == 0 {
 		proxy.PrefixRoutes.CatchAllRoute = &redis.RedisProxy_PrefixRoutes_Route{
 			Cluster: outboundClusterName(targetHost, listenPort),
@@ -128,7 +149,8 @@ func (g *Generator) password(ns string, auth *spec.Auth) (username, password str
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), maxWaitSecret)
 	defer cancel()
-	s, err := g.secretsGetter.Secrets(ns).Get(ctx, secret.Name, v1.GetOptions{})
+	s := &corev1.Secret{}
+	err = g.secretReader.Get(ctx, types.NamespacedName{Namespace: ns, Name: secret.Name}, s)
 	if err != nil {
 		return "", "", err
 	}
@@ -156,60 +178,115 @@ func (g *Generator) password(ns string, auth *spec.Auth) (username, password str
 	return username, password, nil
 }
 
+// buildAuth sets the downstream auth of the proxy. Besides the auth in the settings, the passwords in the secrets
+// listed in the downstream auth secrets annotation are also accepted, so different clients can use their own passwords.
+// The passwords are not mapped to users, any of them is accepted with the username of the settings.
+// An additional secret which can't be read is skipped, so that the other passwords are still required. An error is
+// returned if none of the passwords can be read, the downstream auth must not be turned off by a bad secret reference.
 func (g *Generator) buildAuth(proxy *redis.RedisProxy, rs *v1alpha1.RedisService) error {
-	username, password, err := g.password(rs.Namespace, rs.Spec.Settings.Auth)
+	username, password, err := g.password(rs.Namespace, rs.Spec.Settings.GetAuth())
 	if err != nil {
 		return err
 	}
-	if password != "" {
-		// nolint: staticcheck
-		proxy.DownstreamAuthPassword = inlineString(password)
-	}
 	if username != "" {
 		proxy.DownstreamAuthUsername = inlineString(username)
 	}
+
+	var passwords []*envoycore.DataSource
+	if password != "" {
+		passwords = append(passwords, inlineString(password))
+	}
+	for _, name := range model.GetRedisDownstreamAuthSecrets(rs.Annotations) {
+		_, password, err := g.password(rs.Namespace, &spec.Auth{
+			Auth: &spec.Auth_Secret{Secret: &spec.SecretReference{Name: name}},
+		})
+		if err != nil {
+			generatorLog.Errorf("skipped downstream auth secret %s/%s of RedisService %s: %v",
+				rs.Namespace, name, rs.Name, err)
+			continue
+		}
+		if password != "" {
+			passwords = append(passwords, inlineString(password))
+		}
+	}
+	switch len(passwords) {
+	case 0:
+		if len(model.GetRedisDownstreamAuthSecrets(rs.Annotations)) > 0 {
+			return fmt.Errorf("none of the downstream auth secrets of RedisService %s/%s can be read",
+				rs.Namespace, rs.Name)
+		}
+	case 1:
+		// nolint: staticcheck
+		proxy.DownstreamAuthPassword = passwords[0]
+	default:
+		proxy.DownstreamAuthPasswords = passwords
+	}
 	return nil
 }
 
 func (g *Generator) findTargetHostAndRedisService(ctx context.Context, ns string, hosts []string) (targetHost string,
 	rs *v1alpha1.RedisService, err error) {
 	generatorLog.Debugf("try find target host and RedisService %s %v", ns, hosts)
-	redisServices, err := g.redis.RedisServices(ns).List(ctx, v1.ListOptions{
-		LabelSelector: labels.Everything().String(),
-	})
-	if err != nil {
-		return "", nil, err
-	}
-	svcs := map[string]*v1alpha1.RedisService{}
-	for i := range redisServices.Items {
-		service := redisServices.Items[i]
-		for _, svcHost := range service.Spec.Host {
-			generatorLog.Debugf("related host: %s => %s", svcHost, service.Name)
-			svcs[svcHost] = service
-		}
-	}
 	for _, host := range hosts {
-		rs = svcs[host]
-		if rs != nil {
-			targetHost = host
-			return targetHost, rs, nil
+		redisServices := &v1alpha1.RedisServiceList{}
+		err = g.client.List(ctx, redisServices, client.InNamespace(ns), client.MatchingFields{constants.HostIndex: host})
+		if err != nil {
+			return "", nil, err
+		}
+		if len(redisServices.Items) > 0 {
+			rs = redisServices.Items[0]
+			generatorLog.Debugf("related host: %s => %s", host, rs.Name)
+			return host, rs, nil
 		}
 	}
 	return "", nil, nil
 }
 
+// hostServices returns the service entries visible to the namespace by their hosts, so the routes and mirrors of a
+// RedisService can reference the hosts in other namespaces. The service entries in the namespace take precedence.
 func (g *Generator) hostServices(ns string) (hostServices map[string]*networking.ServiceEntry) {
 	hostServices = map[string]*networking.ServiceEntry{}
-	entries := g.store.List(gvk.ServiceEntry, ns)
+	entries := g.store.List(gvk.ServiceEntry, "")
 	for i := range entries {
-		se := entries[i].Spec.(*networking.ServiceEntry)
+		se, ok := entries[i].Spec.(*networking.ServiceEntry)
+		if !ok || !isVisible(se.ExportTo, entries[i].Namespace, ns) {
+			continue
+		}
 		for _, host := range se.Hosts {
+			if _, exist := hostServices[host]; exist && entries[i].Namespace != ns {
+				continue
+			}
 			hostServices[host] = se
 		}
 	}
 	return hostServices
 }
 
+// isVisible checks whether a service in the namespace with the exportTo is visible to the target namespace.
+// A service without exportTo is visible to all the namespaces.
+func isVisible(exportTo []string, namespace, target string) bool {
+	if len(exportTo) == 0 {
+		return true
+	}
+	for _, e := range exportTo {
+		switch visibility.Instance(e) {
+		case visibility.Public:
+			return true
+		case visibility.None:
+			return false
+		case visibility.Private:
+			if namespace == target {
+				return true
+			}
+		default:
+			if e == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (g *Generator) convertPolicy(policy spec.RedisService_ReadPolicy) redis.RedisProxy_ConnPoolSettings_ReadPolicy {
 	switch policy {
 	case spec.RedisService_MASTER:
@@ -241,9 +318,9 @@ func (g *Generator) buildPrefixRoute(r *spec.RedisService_Route, hostServices ma
 		policy.RuntimeFraction = &envoycore.RuntimeFractionalPercent{
 			DefaultValue: translatePercentToFractionalPercent(mirror.Percentage),
 		}
-		mirrorPort := r.Route.Port
+		mirrorPort := mirror.Route.Port
 		if mirrorPort == 0 {
-			mirrorPort = findServicePort(hostServices[r.Route.Host], listenPort, listenPortName)
+			mirrorPort = findServicePort(hostServices[mirror.Route.Host], listenPort, listenPortName)
 		}
 		policy.Cluster = outboundClusterName(mirror.Route.Host, mirrorPort)
 		route.RequestMirrorPolicy = append(route.RequestMirrorPolicy, policy)
@@ -257,13 +334,7 @@ func (g *Generator) buildPrefixRoute(r *spec.RedisService_Route, hostServices ma
 	return route, false
 }
 
-func (g *Generator) buildSettings(proxy *redis.RedisProxy, rs *v1alpha1.RedisService) (err error) {
-	if rs.Spec.Settings.Auth != nil {
-		err = g.buildAuth(proxy, rs)
-		if err != nil {
-			return err
-		}
-	}
+func (g *Generator) buildSettings(proxy *redis.RedisProxy, rs *v1alpha1.RedisService) error {
 	if rs.Spec.Settings.OpTimeout != nil {
 		proxy.Settings.OpTimeout = rs.Spec.Settings.OpTimeout
 	}
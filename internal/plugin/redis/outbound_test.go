@@ -0,0 +1,256 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"testing"
+
+	spec "github.com/aeraki-mesh/api/redis/v1alpha1"
+	"github.com/aeraki-mesh/client-go/pkg/apis/redis/v1alpha1"
+	envoycore "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	redis "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/redis_proxy/v3"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	networking "istio.io/api/networking/v1alpha3"
+	istioconfig "istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aeraki-mesh/aeraki/internal/model"
+)
+
+func TestIsVisible(t *testing.T) {
+	cases := []struct {
+		name     string
+		exportTo []string
+		target   string
+		want     bool
+	}{
+		{name: "no exportTo", target: "team-a", want: true},
+		{name: "public", exportTo: []string{"*"}, target: "team-a", want: true},
+		{name: "private to the same namespace", exportTo: []string{"."}, target: "redis", want: true},
+		{name: "private to another namespace", exportTo: []string{"."}, target: "team-a", want: false},
+		{name: "exported to the namespace", exportTo: []string{".", "team-a"}, target: "team-a", want: true},
+		{name: "not exported to the namespace", exportTo: []string{"team-b"}, target: "team-a", want: false},
+		{name: "none", exportTo: []string{"~"}, target: "redis", want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := isVisible(c.exportTo, "redis", c.target); got != c.want {
+				t.Errorf("isVisible(%v, redis, %s) = %v, want %v", c.exportTo, c.target, got, c.want)
+			}
+		})
+	}
+}
+
+func TestBuildOutboundProxyRoutes(t *testing.T) {
+	redisService := func(namespace, host string) *v1alpha1.RedisService {
+		return &v1alpha1.RedisService{
+			ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: namespace},
+			Spec: spec.RedisService{
+				Host:  []string{testHost},
+				Redis: []*spec.RedisService_Route{{Route: &spec.RedisService_Destination{Host: host}}},
+			},
+		}
+	}
+	serviceEntry := func(namespace, host string, port uint32, exportTo ...string) istioconfig.Config {
+		return istioconfig.Config{
+			Meta: istioconfig.Meta{GroupVersionKind: gvk.ServiceEntry, Name: host, Namespace: namespace},
+			Spec: &networking.ServiceEntry{
+				Hosts:    []string{host},
+				Ports:    []*networking.ServicePort{{Number: port, Name: "tcp-redis"}},
+				ExportTo: exportTo,
+			},
+		}
+	}
+
+	cases := []struct {
+		name         string
+		redisService *v1alpha1.RedisService
+		wantCluster  string
+	}{
+		{
+			name:         "route to a host in another namespace",
+			redisService: redisService("redis", "shared.cache.svc.cluster.local"),
+			wantCluster:  "outbound|6380||shared.cache.svc.cluster.local",
+		},
+		{
+			// the port of a host which isn't visible can't be resolved, the listen port is used
+			name:         "route to a private host in another namespace",
+			redisService: redisService("redis", "private.team.svc.cluster.local"),
+			wantCluster:  "outbound|6379||private.team.svc.cluster.local",
+		},
+		{
+			// a RedisService in another namespace can't configure the service
+			name:         "RedisService in another namespace",
+			redisService: redisService("tenant", "shared.cache.svc.cluster.local"),
+			wantCluster:  "outbound|6379||" + testHost,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := newTestGenerator(t, c.redisService)
+			for _, config := range []istioconfig.Config{
+				serviceEntry("redis", testHost, 6379),
+				serviceEntry("cache", "shared.cache.svc.cluster.local", 6380),
+				serviceEntry("team", "private.team.svc.cluster.local", 6381, "."),
+			} {
+				if _, err := g.store.Create(config); err != nil {
+					t.Fatal(err)
+				}
+			}
+			proxy, err := g.buildOutboundProxyWithFallback(context.TODO(), testServiceEntry(), 6379, "tcp-redis")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := proxy.GetPrefixRoutes().GetCatchAllRoute().GetCluster(); got != c.wantCluster {
+				t.Errorf("want catch-all cluster %s, got %s", c.wantCluster, got)
+			}
+		})
+	}
+}
+
+func TestBuildPrefixRouteMirrorPort(t *testing.T) {
+	hostServices := map[string]*networking.ServiceEntry{
+		"primary.redis.svc.cluster.local": {Ports: []*networking.ServicePort{{Number: 6380, Name: "tcp-redis"}}},
+		"backup.redis.svc.cluster.local":  {Ports: []*networking.ServicePort{{Number: 6381, Name: "tcp-redis"}}},
+	}
+	r := &spec.RedisService_Route{
+		Route: &spec.RedisService_Destination{Host: "primary.redis.svc.cluster.local"},
+		Mirror: []*spec.RedisService_Mirror{
+			{
+				Route:      &spec.RedisService_Destination{Host: "backup.redis.svc.cluster.local"},
+				Percentage: &spec.Percent{Value: 100},
+			},
+			{
+				Route:      &spec.RedisService_Destination{Host: "backup.redis.svc.cluster.local", Port: 7000},
+				Percentage: &spec.Percent{Value: 100},
+			},
+		},
+	}
+
+	route, _ := (&Generator{}).buildPrefixRoute(r, hostServices, 6379, "tcp-redis")
+	if route.Cluster != "outbound|6380||primary.redis.svc.cluster.local" {
+		t.Errorf("unexpected route cluster: %s", route.Cluster)
+	}
+	want := []string{"outbound|6381||backup.redis.svc.cluster.local", "outbound|7000||backup.redis.svc.cluster.local"}
+	for i, policy := range route.RequestMirrorPolicy {
+		if policy.Cluster != want[i] {
+			t.Errorf("want mirror cluster %s, got %s", want[i], policy.Cluster)
+		}
+	}
+}
+
+func TestBuildAuth(t *testing.T) {
+	secret := func(name, password string) client.Object {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "redis"},
+			Data:       map[string][]byte{"password": []byte(password)},
+		}
+	}
+	settingsAuth := &spec.RedisService_Settings{
+		Auth: &spec.Auth{Auth: &spec.Auth_Plain{Plain: &spec.PlainAuth{Password: "settings"}}},
+	}
+
+	cases := []struct {
+		name       string
+		settings   *spec.RedisService_Settings
+		annotation string
+		want       *redis.RedisProxy
+		wantErr    bool
+	}{
+		{
+			name: "no passwords",
+			want: &redis.RedisProxy{},
+		},
+		{
+			name:     "password of the settings",
+			settings: settingsAuth,
+			want:     &redis.RedisProxy{DownstreamAuthPassword: inlineString("settings")}, // nolint: staticcheck
+		},
+		{
+			name:       "single additional secret",
+			annotation: "client-a",
+			want:       &redis.RedisProxy{DownstreamAuthPassword: inlineString("a")}, // nolint: staticcheck
+		},
+		{
+			name:       "multiple passwords",
+			settings:   settingsAuth,
+			annotation: "client-a, client-b",
+			want: &redis.RedisProxy{DownstreamAuthPasswords: []*envoycore.DataSource{
+				inlineString("settings"), inlineString("a"), inlineString("b"),
+			}},
+		},
+		{
+			name:       "missing additional secret is skipped",
+			settings:   settingsAuth,
+			annotation: "client-a,missing",
+			want: &redis.RedisProxy{DownstreamAuthPasswords: []*envoycore.DataSource{
+				inlineString("settings"), inlineString("a"),
+			}},
+		},
+		{
+			// the downstream auth must not be turned off by bad secret references
+			name:       "no additional secret can be read",
+			annotation: "missing, other",
+			wantErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := newTestGenerator(t, secret("client-a", "a"), secret("client-b", "b"))
+			rs := &v1alpha1.RedisService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "redis",
+					Namespace:   "redis",
+					Annotations: map[string]string{model.RedisDownstreamAuthSecretsAnnotation: c.annotation},
+				},
+				Spec: spec.RedisService{Settings: c.settings},
+			}
+			got := &redis.RedisProxy{}
+			if err := g.buildAuth(got, rs); (err != nil) != c.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.wantErr {
+				return
+			}
+			if diff := cmp.Diff(c.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected auth (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateInvalidDownstreamAuth(t *testing.T) {
+	rs := &v1alpha1.RedisService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "redis",
+			Namespace:   "redis",
+			Annotations: map[string]string{model.RedisDownstreamAuthSecretsAnnotation: "missing"},
+		},
+		Spec: spec.RedisService{Host: []string{testHost}},
+	}
+	g := newTestGenerator(t, rs)
+	// an error keeps the current EnvoyFilters of the service instead of generating a proxy without auth
+	if _, err := g.Generate(testServiceEntry()); err == nil {
+		t.Errorf("expected an error for unreadable downstream auth secrets")
+	}
+}
@@ -16,25 +16,31 @@ package redis
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"strconv"
 	"strings"
+	"time"
 
 	spec "github.com/aeraki-mesh/api/redis/v1alpha1"
+	"github.com/aeraki-mesh/client-go/pkg/apis/redis/v1alpha1"
 	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	envoycore "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	rediscluster "github.com/envoyproxy/go-control-plane/envoy/extensions/clusters/redis/v3"
 	redis "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/redis_proxy/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/golang/protobuf/ptypes/duration"
 	structpb "github.com/golang/protobuf/ptypes/struct"
 	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/hashicorp/go-multierror"
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"istio.io/istio/pilot/pkg/xds/filters"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/aeraki-mesh/aeraki/internal/config/constants"
 	"github.com/aeraki-mesh/aeraki/internal/model"
 )
 
@@ -63,27 +69,15 @@ func (g *Generator) buildOutboundCluster(ctx context.Context, c *model.EnvoyFilt
 		},
 	}
 
-	destinations, err := g.redis.RedisDestinations(c.ServiceEntry.Namespace).List(ctx, v1.ListOptions{
-		LabelSelector: labels.Everything().String(),
-	})
+	destination, err := g.findRedisDestination(ctx, c.ServiceEntry.Namespace, c.ServiceEntry.Spec.Hosts)
 	if err != nil {
 		generatorLog.Errorf("could not list RedisDestinations: %e", err)
 		return nil
 	}
-	var targetDestination *spec.RedisDestination
-L:
-	for i := range destinations.Items {
-		destination := destinations.Items[i]
-		for _, host := range c.ServiceEntry.Spec.Hosts {
-			if destination.Spec.Host == host {
-				targetDestination = &destination.Spec
-				break L
-			}
-		}
-	}
-	if targetDestination == nil {
+	if destination == nil {
 		return cl
 	}
+	targetDestination := &destination.Spec
 	if targetDestination.TrafficPolicy == nil {
 		return cl
 	}
@@ -96,9 +90,21 @@ L:
 	if connPool.Redis != nil {
 		if connPool.Redis.Mode == spec.RedisSettings_CLUSTER {
 			cl.LbPolicy = cluster.Cluster_CLUSTER_PROVIDED
-			cl.ClusterDiscoveryType = &cluster.Cluster_ClusterType{ClusterType: &cluster.Cluster_CustomClusterType{
+			clusterType := &cluster.Cluster_CustomClusterType{
 				Name: "envoy.clusters.redis",
-			}}
+			}
+			// the invalid refresh settings are left to their defaults, the cluster is still a redis cluster
+			refreshConfig, err := buildRedisClusterConfig(destination.Annotations)
+			if err != nil {
+				generatorLog.Errorf("invalid refresh settings of %s/%s: %v", destination.Namespace, destination.Name, err)
+			}
+			if refreshConfig != nil {
+				if clusterType.TypedConfig, err = anypb.New(refreshConfig); err != nil {
+					generatorLog.Errorf("RedisClusterConfig create failed: %e", err)
+					return nil
+				}
+			}
+			cl.ClusterDiscoveryType = &cluster.Cluster_ClusterType{ClusterType: clusterType}
 			cl.EdsClusterConfig = nil
 			var hostports []HostPort
 			if len(connPool.Redis.DiscoveryEndpoints) != 0 {
@@ -168,7 +174,7 @@ L:
 	g.addIstioFilter(cl, listenPort, targetDestination.Host, c.ServiceEntry.Name, c.ServiceEntry.Namespace)
 
 	// The `mTLS` cannot works with redis proxy now.
-	// So we just use raw buffer
+	// So we just use raw buffer, unless TLS is configured in the DestinationRule
 	// see https://github.com/istio/istio/issues/30022 .
 	cl.TransportSocketMatches = []*cluster.Cluster_TransportSocketMatch{
 		{
@@ -179,6 +185,94 @@ L:
 	return cl
 }
 
+// The refresh settings of a redis cluster are specified in the annotations of the RedisDestination
+const (
+	clusterRefreshRateAnnotation           = "redis.aeraki.io/clusterRefreshRate"
+	clusterRefreshTimeoutAnnotation        = "redis.aeraki.io/clusterRefreshTimeout"
+	redirectRefreshIntervalAnnotation      = "redis.aeraki.io/redirectRefreshInterval"
+	redirectRefreshThresholdAnnotation     = "redis.aeraki.io/redirectRefreshThreshold"
+	failureRefreshThresholdAnnotation      = "redis.aeraki.io/failureRefreshThreshold"
+	hostDegradedRefreshThresholdAnnotation = "redis.aeraki.io/hostDegradedRefreshThreshold"
+)
+
+// ValidateAnnotations validates the refresh settings in the annotations of a RedisDestination
+func ValidateAnnotations(annotations map[string]string) error {
+	_, err := buildRedisClusterConfig(annotations)
+	return err
+}
+
+// buildRedisClusterConfig builds the config of envoy.clusters.redis from the refresh settings, nil is returned if
+// none of them is specified. Invalid settings are reported in the error and left to their defaults.
+func buildRedisClusterConfig(annotations map[string]string) (*rediscluster.RedisClusterConfig, error) {
+	config := &rediscluster.RedisClusterConfig{}
+	found := false
+	var errs *multierror.Error
+	for annotation, field := range map[string]**duration.Duration{
+		clusterRefreshRateAnnotation:      &config.ClusterRefreshRate,
+		clusterRefreshTimeoutAnnotation:   &config.ClusterRefreshTimeout,
+		redirectRefreshIntervalAnnotation: &config.RedirectRefreshInterval,
+	} {
+		if value, ok := annotations[annotation]; ok {
+			d, err := time.ParseDuration(value)
+			if err == nil && d <= 0 {
+				err = fmt.Errorf("duration must be positive")
+			}
+			if err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("invalid %s annotation: %v", annotation, err))
+				continue
+			}
+			*field = durationpb.New(d)
+			found = true
+		}
+	}
+	for annotation, field := range map[string]*uint32{
+		failureRefreshThresholdAnnotation:      &config.FailureRefreshThreshold,
+		hostDegradedRefreshThresholdAnnotation: &config.HostDegradedRefreshThreshold,
+	} {
+		if value, ok := annotations[annotation]; ok {
+			threshold, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("invalid %s annotation: %v", annotation, err))
+				continue
+			}
+			*field = uint32(threshold)
+			found = true
+		}
+	}
+	if value, ok := annotations[redirectRefreshThresholdAnnotation]; ok {
+		threshold, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("invalid %s annotation: %v", redirectRefreshThresholdAnnotation, err))
+		} else {
+			config.RedirectRefreshThreshold = &wrappers.UInt32Value{Value: uint32(threshold)}
+			found = true
+		}
+	}
+	if !found {
+		return nil, errs.ErrorOrNil()
+	}
+	if err := config.ValidateAll(); err != nil {
+		return nil, multierror.Append(errs, err)
+	}
+	return config, errs.ErrorOrNil()
+}
+
+// findRedisDestination returns the RedisDestination of the first host which has one
+func (g *Generator) findRedisDestination(ctx context.Context, ns string,
+	hosts []string) (*v1alpha1.RedisDestination, error) {
+	for _, host := range hosts {
+		destinations := &v1alpha1.RedisDestinationList{}
+		err := g.client.List(ctx, destinations, client.InNamespace(ns), client.MatchingFields{constants.HostIndex: host})
+		if err != nil {
+			return nil, err
+		}
+		if len(destinations.Items) > 0 {
+			return destinations.Items[0], nil
+		}
+	}
+	return nil, nil
+}
+
 func (g *Generator) addIstioFilter(cl *cluster.Cluster, port uint32, host, name, namespace string) {
 	name = strings.TrimPrefix(name, "synthetic-")
 	metadata := getOrCreateIstioMetadata(cl)
@@ -0,0 +1,126 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	spec "github.com/aeraki-mesh/api/redis/v1alpha1"
+	"github.com/aeraki-mesh/client-go/pkg/apis/redis/v1alpha1"
+	rediscluster "github.com/envoyproxy/go-control-plane/envoy/extensions/clusters/redis/v3"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildRedisClusterConfig(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		want        *rediscluster.RedisClusterConfig
+		wantErr     bool
+	}{
+		{
+			name:        "no refresh settings",
+			annotations: map[string]string{"interface": "foo"},
+		},
+		{
+			name: "refresh settings",
+			annotations: map[string]string{
+				clusterRefreshRateAnnotation:       "10s",
+				redirectRefreshThresholdAnnotation: "5",
+				failureRefreshThresholdAnnotation:  "3",
+			},
+			want: &rediscluster.RedisClusterConfig{
+				ClusterRefreshRate:       durationpb.New(10 * time.Second),
+				RedirectRefreshThreshold: &wrappers.UInt32Value{Value: 5},
+				FailureRefreshThreshold:  3,
+			},
+		},
+		{
+			name:        "invalid duration",
+			annotations: map[string]string{clusterRefreshTimeoutAnnotation: "10"},
+			wantErr:     true,
+		},
+		{
+			name:        "non-positive duration",
+			annotations: map[string]string{clusterRefreshRateAnnotation: "0s"},
+			wantErr:     true,
+		},
+		{
+			name:        "invalid threshold",
+			annotations: map[string]string{hostDegradedRefreshThresholdAnnotation: "-1"},
+			wantErr:     true,
+		},
+		{
+			// the valid settings are kept and the invalid ones are left to their defaults
+			name: "partially invalid settings",
+			annotations: map[string]string{
+				clusterRefreshRateAnnotation:           "10s",
+				clusterRefreshTimeoutAnnotation:        "10",
+				failureRefreshThresholdAnnotation:      "3",
+				hostDegradedRefreshThresholdAnnotation: "-1",
+			},
+			want: &rediscluster.RedisClusterConfig{
+				ClusterRefreshRate:      durationpb.New(10 * time.Second),
+				FailureRefreshThreshold: 3,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := buildRedisClusterConfig(c.annotations)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(c.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected config (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBuildOutboundClusterInvalidRefreshSettings(t *testing.T) {
+	destination := &v1alpha1.RedisDestination{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "redis",
+			Namespace:   "redis",
+			Annotations: map[string]string{clusterRefreshRateAnnotation: "10"},
+		},
+		Spec: spec.RedisDestination{
+			Host: testHost,
+			TrafficPolicy: &spec.TrafficPolicy{
+				ConnectionPool: &spec.ConnectionPoolSettings{
+					Redis: &spec.RedisSettings{Mode: spec.RedisSettings_CLUSTER},
+				},
+			},
+		},
+	}
+	g := newTestGenerator(t, destination)
+
+	cl := g.buildOutboundCluster(context.TODO(), testServiceEntry(), 6379)
+	if got := cl.GetClusterType().GetName(); got != "envoy.clusters.redis" {
+		t.Fatalf("want redis cluster type, got %q", got)
+	}
+	if cl.GetClusterType().GetTypedConfig() != nil {
+		t.Errorf("want default refresh settings, got %v", cl.GetClusterType().GetTypedConfig())
+	}
+}
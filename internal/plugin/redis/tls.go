@@ -0,0 +1,147 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"fmt"
+
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	envoycore "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"google.golang.org/protobuf/types/known/anypb"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model/credentials"
+	securitymodel "istio.io/istio/pilot/pkg/security/model"
+	"istio.io/istio/pkg/config/schema/gvk"
+
+	"github.com/aeraki-mesh/aeraki/internal/model"
+)
+
+// setUpstreamTLS sets the TLS transport socket of a redis cluster according to the TLS settings in the DestinationRule
+// of the service. Only SIMPLE and MUTUAL modes are supported, Istio mTLS can't work with the redis proxy.
+func (g *Generator) setUpstreamTLS(cl *cluster.Cluster, service *model.ServiceEntryWrapper, port uint32) error {
+	settings := g.findTLSSettings(service, port)
+	if settings == nil {
+		return nil
+	}
+	switch settings.Mode {
+	case networking.ClientTLSSettings_SIMPLE, networking.ClientTLSSettings_MUTUAL:
+	case networking.ClientTLSSettings_ISTIO_MUTUAL:
+		generatorLog.Warnf("ISTIO_MUTUAL tls mode is not supported by redis cluster %s", cl.Name)
+		return nil
+	default:
+		return nil
+	}
+
+	tlsContext, err := buildUpstreamTLSContext(settings)
+	if err != nil {
+		return err
+	}
+	typedConfig, err := anypb.New(tlsContext)
+	if err != nil {
+		return err
+	}
+	cl.TransportSocketMatches = nil
+	cl.TransportSocket = &envoycore.TransportSocket{
+		Name:       wellknown.TransportSocketTLS,
+		ConfigType: &envoycore.TransportSocket_TypedConfig{TypedConfig: typedConfig},
+	}
+	return nil
+}
+
+// buildUpstreamTLSContext builds an UpstreamTlsContext from the client TLS settings of a DestinationRule.
+// The certificates of a credentialName are fetched through SDS from Istiod, otherwise they are read from files.
+// An error is returned if the server certificate can't be verified, unless insecureSkipVerify is set.
+func buildUpstreamTLSContext(settings *networking.ClientTLSSettings) (*tlsv3.UpstreamTlsContext, error) {
+	tlsContext := &tlsv3.UpstreamTlsContext{
+		CommonTlsContext: &tlsv3.CommonTlsContext{},
+		Sni:              settings.Sni,
+	}
+	common := tlsContext.CommonTlsContext
+
+	if settings.Mode == networking.ClientTLSSettings_MUTUAL {
+		switch {
+		case settings.CredentialName != "":
+			common.TlsCertificateSdsSecretConfigs = []*tlsv3.SdsSecretConfig{
+				securitymodel.ConstructSdsSecretConfigForCredential(settings.CredentialName, false),
+			}
+		case settings.ClientCertificate != "" && settings.PrivateKey != "":
+			common.TlsCertificates = []*tlsv3.TlsCertificate{{
+				CertificateChain: fileDataSource(settings.ClientCertificate),
+				PrivateKey:       fileDataSource(settings.PrivateKey),
+			}}
+		default:
+			return nil, fmt.Errorf("client certificate is required for MUTUAL tls mode")
+		}
+	}
+
+	if settings.GetInsecureSkipVerify().GetValue() {
+		return tlsContext, nil
+	}
+	var sans []*tlsv3.SubjectAltNameMatcher
+	for _, san := range settings.SubjectAltNames {
+		sans = append(sans, &tlsv3.SubjectAltNameMatcher{
+			SanType: tlsv3.SubjectAltNameMatcher_DNS,
+			Matcher: &matcher.StringMatcher{MatchPattern: &matcher.StringMatcher_Exact{Exact: san}},
+		})
+	}
+	validationContext := &tlsv3.CertificateValidationContext{MatchTypedSubjectAltNames: sans}
+	switch {
+	case settings.CredentialName != "":
+		common.ValidationContextType = &tlsv3.CommonTlsContext_CombinedValidationContext{
+			CombinedValidationContext: &tlsv3.CommonTlsContext_CombinedCertificateValidationContext{
+				DefaultValidationContext: validationContext,
+				ValidationContextSdsSecretConfig: securitymodel.ConstructSdsSecretConfigForCredential(
+					settings.CredentialName+credentials.SdsCaSuffix, false),
+			},
+		}
+	case settings.CaCertificates != "":
+		validationContext.TrustedCa = fileDataSource(settings.CaCertificates)
+		common.ValidationContextType = &tlsv3.CommonTlsContext_ValidationContext{
+			ValidationContext: validationContext,
+		}
+	default:
+		// the server certificate can't be verified without a trusted CA
+		return nil, fmt.Errorf("caCertificates or credentialName is required unless insecureSkipVerify is set")
+	}
+	return tlsContext, nil
+}
+
+// findTLSSettings returns the TLS settings of a port in the DestinationRule of the service, the port level settings
+// take precedence over the service level ones.
+func (g *Generator) findTLSSettings(service *model.ServiceEntryWrapper, port uint32) *networking.ClientTLSSettings {
+	drs := g.store.List(gvk.DestinationRule, "")
+	for i := range drs {
+		dr, ok := drs[i].Spec.(*networking.DestinationRule)
+		if !ok || !model.IsFQDNEquals(dr.Host, drs[i].Namespace, service.Spec.Hosts[0], service.Namespace) {
+			continue
+		}
+		for _, portSettings := range dr.GetTrafficPolicy().GetPortLevelSettings() {
+			if portSettings.GetPort().GetNumber() == port && portSettings.Tls != nil {
+				return portSettings.Tls
+			}
+		}
+		return dr.GetTrafficPolicy().GetTls()
+	}
+	return nil
+}
+
+func fileDataSource(filename string) *envoycore.DataSource {
+	return &envoycore.DataSource{
+		Specifier: &envoycore.DataSource_Filename{Filename: filename},
+	}
+}
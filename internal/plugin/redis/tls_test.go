@@ -0,0 +1,279 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"testing"
+
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model/credentials"
+	securitymodel "istio.io/istio/pilot/pkg/security/model"
+	istioconfig "istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+)
+
+func destinationRule(host string, trafficPolicy *networking.TrafficPolicy) istioconfig.Config {
+	return istioconfig.Config{
+		Meta: istioconfig.Meta{
+			GroupVersionKind: gvk.DestinationRule,
+			Name:             "redis",
+			Namespace:        "redis",
+		},
+		Spec: &networking.DestinationRule{Host: host, TrafficPolicy: trafficPolicy},
+	}
+}
+
+func TestBuildUpstreamTLSContext(t *testing.T) {
+	sans := []*tlsv3.SubjectAltNameMatcher{{
+		SanType: tlsv3.SubjectAltNameMatcher_DNS,
+		Matcher: &matcher.StringMatcher{MatchPattern: &matcher.StringMatcher_Exact{Exact: "redis.example.com"}},
+	}}
+
+	cases := []struct {
+		name     string
+		settings *networking.ClientTLSSettings
+		want     *tlsv3.UpstreamTlsContext
+		wantErr  bool
+	}{
+		{
+			name: "simple with ca certificates",
+			settings: &networking.ClientTLSSettings{
+				Mode:            networking.ClientTLSSettings_SIMPLE,
+				CaCertificates:  "/etc/certs/ca.pem",
+				SubjectAltNames: []string{"redis.example.com"},
+				Sni:             "redis.example.com",
+			},
+			want: &tlsv3.UpstreamTlsContext{
+				Sni: "redis.example.com",
+				CommonTlsContext: &tlsv3.CommonTlsContext{
+					ValidationContextType: &tlsv3.CommonTlsContext_ValidationContext{
+						ValidationContext: &tlsv3.CertificateValidationContext{
+							TrustedCa:                 fileDataSource("/etc/certs/ca.pem"),
+							MatchTypedSubjectAltNames: sans,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "mutual with certificate files",
+			settings: &networking.ClientTLSSettings{
+				Mode:              networking.ClientTLSSettings_MUTUAL,
+				ClientCertificate: "/etc/certs/cert.pem",
+				PrivateKey:        "/etc/certs/key.pem",
+				CaCertificates:    "/etc/certs/ca.pem",
+			},
+			want: &tlsv3.UpstreamTlsContext{
+				CommonTlsContext: &tlsv3.CommonTlsContext{
+					TlsCertificates: []*tlsv3.TlsCertificate{{
+						CertificateChain: fileDataSource("/etc/certs/cert.pem"),
+						PrivateKey:       fileDataSource("/etc/certs/key.pem"),
+					}},
+					ValidationContextType: &tlsv3.CommonTlsContext_ValidationContext{
+						ValidationContext: &tlsv3.CertificateValidationContext{
+							TrustedCa: fileDataSource("/etc/certs/ca.pem"),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "mutual with credential name",
+			settings: &networking.ClientTLSSettings{
+				Mode:           networking.ClientTLSSettings_MUTUAL,
+				CredentialName: "redis-cert",
+			},
+			want: &tlsv3.UpstreamTlsContext{
+				CommonTlsContext: &tlsv3.CommonTlsContext{
+					TlsCertificateSdsSecretConfigs: []*tlsv3.SdsSecretConfig{
+						securitymodel.ConstructSdsSecretConfigForCredential("redis-cert", false),
+					},
+					ValidationContextType: &tlsv3.CommonTlsContext_CombinedValidationContext{
+						CombinedValidationContext: &tlsv3.CommonTlsContext_CombinedCertificateValidationContext{
+							DefaultValidationContext: &tlsv3.CertificateValidationContext{},
+							ValidationContextSdsSecretConfig: securitymodel.ConstructSdsSecretConfigForCredential(
+								"redis-cert"+credentials.SdsCaSuffix, false),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "mutual without client certificate",
+			settings: &networking.ClientTLSSettings{
+				Mode:       networking.ClientTLSSettings_MUTUAL,
+				PrivateKey: "/etc/certs/key.pem",
+			},
+			wantErr: true,
+		},
+		{
+			name: "simple without ca certificates",
+			settings: &networking.ClientTLSSettings{
+				Mode:            networking.ClientTLSSettings_SIMPLE,
+				SubjectAltNames: []string{"redis.example.com"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mutual without ca certificates",
+			settings: &networking.ClientTLSSettings{
+				Mode:              networking.ClientTLSSettings_MUTUAL,
+				ClientCertificate: "/etc/certs/cert.pem",
+				PrivateKey:        "/etc/certs/key.pem",
+			},
+			wantErr: true,
+		},
+		{
+			name: "insecure skip verify",
+			settings: &networking.ClientTLSSettings{
+				Mode:               networking.ClientTLSSettings_SIMPLE,
+				CaCertificates:     "/etc/certs/ca.pem",
+				InsecureSkipVerify: wrapperspb.Bool(true),
+			},
+			want: &tlsv3.UpstreamTlsContext{CommonTlsContext: &tlsv3.CommonTlsContext{}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := buildUpstreamTLSContext(c.settings)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("want error %v, got %v", c.wantErr, err)
+			}
+			if diff := cmp.Diff(c.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected tls context (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFindTLSSettings(t *testing.T) {
+	serviceTLS := &networking.ClientTLSSettings{Mode: networking.ClientTLSSettings_SIMPLE}
+	portTLS := &networking.ClientTLSSettings{Mode: networking.ClientTLSSettings_MUTUAL, CredentialName: "redis-cert"}
+
+	cases := []struct {
+		name string
+		dr   *istioconfig.Config
+		port uint32
+		want *networking.ClientTLSSettings
+	}{
+		{name: "no destination rule", port: 6379},
+		{
+			name: "service level settings",
+			dr: func() *istioconfig.Config {
+				dr := destinationRule(testHost, &networking.TrafficPolicy{Tls: serviceTLS})
+				return &dr
+			}(),
+			port: 6379,
+			want: serviceTLS,
+		},
+		{
+			name: "port level settings take precedence",
+			dr: func() *istioconfig.Config {
+				dr := destinationRule("redis", &networking.TrafficPolicy{
+					Tls: serviceTLS,
+					PortLevelSettings: []*networking.TrafficPolicy_PortTrafficPolicy{{
+						Port: &networking.PortSelector{Number: 6379},
+						Tls:  portTLS,
+					}},
+				})
+				return &dr
+			}(),
+			port: 6379,
+			want: portTLS,
+		},
+		{
+			name: "destination rule of another host",
+			dr: func() *istioconfig.Config {
+				dr := destinationRule("cache.redis.svc.cluster.local", &networking.TrafficPolicy{Tls: serviceTLS})
+				return &dr
+			}(),
+			port: 6379,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := newTestGenerator(t)
+			if c.dr != nil {
+				if _, err := g.store.Create(*c.dr); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := g.findTLSSettings(testServiceEntry().ServiceEntry, c.port)
+			if diff := cmp.Diff(c.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected tls settings (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateSkipsClusterWithInvalidTLS(t *testing.T) {
+	cases := []struct {
+		name        string
+		tls         *networking.ClientTLSSettings
+		wantCluster bool
+	}{
+		{
+			name: "valid tls",
+			tls: &networking.ClientTLSSettings{
+				Mode:           networking.ClientTLSSettings_SIMPLE,
+				CaCertificates: "/etc/certs/ca.pem",
+			},
+			wantCluster: true,
+		},
+		{
+			name: "mutual without client certificate",
+			tls: &networking.ClientTLSSettings{
+				Mode:           networking.ClientTLSSettings_MUTUAL,
+				CaCertificates: "/etc/certs/ca.pem",
+			},
+		},
+		{
+			name: "simple without ca certificates",
+			tls:  &networking.ClientTLSSettings{Mode: networking.ClientTLSSettings_SIMPLE},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := newTestGenerator(t)
+			if _, err := g.store.Create(destinationRule(testHost, &networking.TrafficPolicy{Tls: c.tls})); err != nil {
+				t.Fatal(err)
+			}
+			filters, err := g.Generate(testServiceEntry())
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotCluster := false
+			for _, filter := range filters {
+				for _, patch := range filter.Envoyfilter.ConfigPatches {
+					if patch.ApplyTo == networking.EnvoyFilter_CLUSTER {
+						gotCluster = true
+					}
+				}
+			}
+			// a redis cluster with invalid tls settings must not be downgraded to plaintext
+			if gotCluster != c.wantCluster {
+				t.Errorf("want redis cluster %v, got %v", c.wantCluster, gotCluster)
+			}
+		})
+	}
+}
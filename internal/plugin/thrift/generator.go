@@ -15,10 +15,20 @@
 package thrift
 
 import (
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	thrift "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/thrift_proxy/v3"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/pkg/log"
+
 	"github.com/aeraki-mesh/aeraki/internal/envoyfilter"
 	"github.com/aeraki-mesh/aeraki/internal/model"
 )
 
+var generatorLog = log.RegisterScope("thrift-generator", "thrift generator", 0)
+
 // Generator defines a Thrift envoyfilter Generator
 type Generator struct {
 }
@@ -30,11 +40,69 @@ func NewGenerator() *Generator {
 
 // Generate create EnvoyFilters for Thrift services
 func (*Generator) Generate(context *model.EnvoyFilterContext) ([]*model.EnvoyFilterWrapper, error) {
-	return envoyfilter.GenerateReplaceNetworkFilter(
+	o, err := parseOptions(context.ServiceEntry.Annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	envoyFilters := envoyfilter.GenerateReplaceNetworkFilter(
 		context.ServiceEntry,
 		context.ServiceEntry.Spec.Ports[0],
-		buildOutboundProxy(context),
-		buildInboundProxy(context),
+		buildOutboundProxy(context, o),
+		buildInboundProxy(context, o),
 		"envoy.filters.network.thrift_proxy",
-		"type.googleapis.com/envoy.extensions.filters.network.thrift_proxy.v3.ThriftProxy"), nil
+		"type.googleapis.com/envoy.extensions.filters.network.thrift_proxy.v3.ThriftProxy")
+
+	if o.upstream != nil {
+		patch, err := buildUpstreamProtocolPatch(context.ServiceEntry.Spec, o.upstream)
+		if err != nil {
+			return nil, err
+		}
+		// the outbound EnvoyFilters don't have a workload selector
+		for _, envoyFilter := range envoyFilters {
+			if envoyFilter.Envoyfilter.WorkloadSelector == nil {
+				envoyFilter.Envoyfilter.ConfigPatches = append(envoyFilter.Envoyfilter.ConfigPatches, patch)
+			}
+		}
+	}
+	return envoyFilters, nil
+}
+
+// buildUpstreamProtocolPatch builds a patch that sets the Thrift protocol options of the outbound clusters, so the
+// Thrift proxy converts the requests to the upstream transport and protocol
+func buildUpstreamProtocolPatch(service *networking.ServiceEntry,
+	upstream *thrift.ThriftProtocolOptions) (*networking.EnvoyFilter_EnvoyConfigObjectPatch, error) {
+	typedOptions, err := anypb.New(upstream)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := protojson.Marshal(&cluster.Cluster{
+		TypedExtensionProtocolOptions: map[string]*anypb.Any{
+			"envoy.filters.network.thrift_proxy": typedOptions,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	value := &structpb.Struct{}
+	if err := protojson.Unmarshal(buf, value); err != nil {
+		return nil, err
+	}
+
+	return &networking.EnvoyFilter_EnvoyConfigObjectPatch{
+		ApplyTo: networking.EnvoyFilter_CLUSTER,
+		Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+			Context: networking.EnvoyFilter_SIDECAR_OUTBOUND,
+			ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Cluster{
+				Cluster: &networking.EnvoyFilter_ClusterMatch{
+					Service:    service.Hosts[0],
+					PortNumber: service.Ports[0].Number,
+				},
+			},
+		},
+		Patch: &networking.EnvoyFilter_Patch{
+			Operation: networking.EnvoyFilter_Patch_MERGE,
+			Value:     value,
+		},
+	}, nil
 }
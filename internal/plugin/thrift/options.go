@@ -0,0 +1,202 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package thrift
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	routepb "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	// register the Thrift filters, so their typed configs can be resolved
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/thrift_proxy/filters/header_to_metadata/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/thrift_proxy/filters/payload_to_metadata/v3"
+	_ "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/thrift_proxy/filters/ratelimit/v3"
+	thrift "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/thrift_proxy/v3"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// transportAnnotation specifies the downstream transport: auto, framed, unframed or header
+	transportAnnotation = "thrift.aeraki.io/transport"
+	// protocolAnnotation specifies the downstream protocol: auto, binary, lax_binary, compact or twitter
+	protocolAnnotation = "thrift.aeraki.io/protocol"
+	// upstreamTransportAnnotation specifies the transport used for upstream connections, defaults to the downstream
+	// transport
+	upstreamTransportAnnotation = "thrift.aeraki.io/upstreamTransport"
+	// upstreamProtocolAnnotation specifies the protocol used for upstream connections, defaults to the downstream
+	// protocol
+	upstreamProtocolAnnotation = "thrift.aeraki.io/upstreamProtocol"
+	// filtersAnnotation specifies a JSON array of Thrift filters, which are inserted before the router of the inbound
+	// Thrift proxy
+	filtersAnnotation = "thrift.aeraki.io/filters"
+	// rateLimitsAnnotation specifies a JSON array of rate limit actions, which are applied to the inbound route of the
+	// service and used by the rate_limit filter to build the descriptors
+	rateLimitsAnnotation = "thrift.aeraki.io/rateLimits"
+)
+
+// supportedFilters are the Thrift filters which can be configured through the filters annotation
+var supportedFilters = map[string]bool{
+	"envoy.filters.thrift.rate_limit":          true,
+	"envoy.filters.thrift.header_to_metadata":  true,
+	"envoy.filters.thrift.payload_to_metadata": true,
+}
+
+// options defines the Thrift proxy options of a service
+type options struct {
+	transport thrift.TransportType
+	protocol  thrift.ProtocolType
+	// upstream is nil if the upstream transport and protocol are the same as the downstream ones
+	upstream   *thrift.ThriftProtocolOptions
+	filters    []*thrift.ThriftFilter
+	rateLimits []*routepb.RateLimit
+}
+
+// ValidateAnnotations validates the Thrift proxy options in the annotations of a ServiceEntry
+func ValidateAnnotations(annotations map[string]string) error {
+	_, err := parseOptions(annotations)
+	return err
+}
+
+// parseOptions parses the Thrift proxy options from the annotations of a ServiceEntry
+func parseOptions(annotations map[string]string) (*options, error) {
+	var err error
+	o := &options{}
+	if o.transport, err = parseTransport(annotations[transportAnnotation]); err != nil {
+		return nil, err
+	}
+	if o.protocol, err = parseProtocol(annotations[protocolAnnotation]); err != nil {
+		return nil, err
+	}
+
+	if annotations[upstreamTransportAnnotation] != "" || annotations[upstreamProtocolAnnotation] != "" {
+		o.upstream = &thrift.ThriftProtocolOptions{
+			Transport: o.transport,
+			Protocol:  o.protocol,
+		}
+		if annotations[upstreamTransportAnnotation] != "" {
+			if o.upstream.Transport, err = parseTransport(annotations[upstreamTransportAnnotation]); err != nil {
+				return nil, err
+			}
+		}
+		if annotations[upstreamProtocolAnnotation] != "" {
+			if o.upstream.Protocol, err = parseProtocol(annotations[upstreamProtocolAnnotation]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if o.filters, err = parseFilters(annotations[filtersAnnotation]); err != nil {
+		return nil, err
+	}
+	if o.rateLimits, err = parseRateLimits(annotations[rateLimitsAnnotation]); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func parseTransport(value string) (thrift.TransportType, error) {
+	if value == "" || value == "auto" {
+		return thrift.TransportType_AUTO_TRANSPORT, nil
+	}
+	transport, ok := thrift.TransportType_value[strings.ToUpper(value)]
+	if !ok {
+		return thrift.TransportType_AUTO_TRANSPORT, fmt.Errorf("unsupported thrift transport: %s", value)
+	}
+	return thrift.TransportType(transport), nil
+}
+
+func parseProtocol(value string) (thrift.ProtocolType, error) {
+	if value == "" || value == "auto" {
+		return thrift.ProtocolType_AUTO_PROTOCOL, nil
+	}
+	protocol, ok := thrift.ProtocolType_value[strings.ToUpper(value)]
+	if !ok {
+		return thrift.ProtocolType_AUTO_PROTOCOL, fmt.Errorf("unsupported thrift protocol: %s", value)
+	}
+	return thrift.ProtocolType(protocol), nil
+}
+
+func parseFilters(value string) ([]*thrift.ThriftFilter, error) {
+	var filters []*thrift.ThriftFilter
+	err := unmarshalJSONArray(value, func(item []byte) error {
+		filter := &thrift.ThriftFilter{}
+		if err := protojson.Unmarshal(item, filter); err != nil {
+			return err
+		}
+		if !supportedFilters[filter.Name] {
+			return fmt.Errorf("unsupported thrift filter: %s", filter.Name)
+		}
+		if filter.GetTypedConfig() == nil {
+			return fmt.Errorf("typed_config of thrift filter %s is required", filter.Name)
+		}
+		config, err := filter.GetTypedConfig().UnmarshalNew()
+		if err != nil {
+			return err
+		}
+		if err := validate(config); err != nil {
+			return fmt.Errorf("invalid config of thrift filter %s: %v", filter.Name, err)
+		}
+		filters = append(filters, filter)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", filtersAnnotation, err)
+	}
+	return filters, nil
+}
+
+func parseRateLimits(value string) ([]*routepb.RateLimit, error) {
+	var rateLimits []*routepb.RateLimit
+	err := unmarshalJSONArray(value, func(item []byte) error {
+		rateLimit := &routepb.RateLimit{}
+		if err := protojson.Unmarshal(item, rateLimit); err != nil {
+			return err
+		}
+		if err := rateLimit.ValidateAll(); err != nil {
+			return err
+		}
+		rateLimits = append(rateLimits, rateLimit)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", rateLimitsAnnotation, err)
+	}
+	return rateLimits, nil
+}
+
+func unmarshalJSONArray(value string, unmarshal func(item []byte) error) error {
+	if value == "" {
+		return nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(value), &items); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := unmarshal(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validate(config proto.Message) error {
+	if v, ok := config.(interface{ ValidateAll() error }); ok {
+		return v.ValidateAll()
+	}
+	return nil
+}
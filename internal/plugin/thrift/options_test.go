@@ -0,0 +1,104 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package thrift
+
+import (
+	"testing"
+
+	thrift "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/thrift_proxy/v3"
+)
+
+func TestParseOptions(t *testing.T) {
+	cases := []struct {
+		name          string
+		annotations   map[string]string
+		wantTransport thrift.TransportType
+		wantProtocol  thrift.ProtocolType
+		wantUpstream  *thrift.ThriftProtocolOptions
+		wantFilters   int
+		wantErr       bool
+	}{
+		{
+			name:          "default",
+			annotations:   map[string]string{},
+			wantTransport: thrift.TransportType_AUTO_TRANSPORT,
+			wantProtocol:  thrift.ProtocolType_AUTO_PROTOCOL,
+		},
+		{
+			name: "transport and protocol",
+			annotations: map[string]string{
+				transportAnnotation:        "framed",
+				protocolAnnotation:         "binary",
+				upstreamProtocolAnnotation: "compact",
+			},
+			wantTransport: thrift.TransportType_FRAMED,
+			wantProtocol:  thrift.ProtocolType_BINARY,
+			wantUpstream: &thrift.ThriftProtocolOptions{
+				Transport: thrift.TransportType_FRAMED,
+				Protocol:  thrift.ProtocolType_COMPACT,
+			},
+		},
+		{
+			name:        "invalid transport",
+			annotations: map[string]string{transportAnnotation: "http"},
+			wantErr:     true,
+		},
+		{
+			name: "rate limit filter",
+			annotations: map[string]string{filtersAnnotation: `[{"name": "envoy.filters.thrift.rate_limit",
+"typed_config": {"@type": "type.googleapis.com/envoy.extensions.filters.network.thrift_proxy.filters.ratelimit.v3.RateLimit",
+"domain": "thrift", "rate_limit_service": {"grpc_service": {"envoy_grpc": {"cluster_name": "ratelimit"}},
+"transport_api_version": "V3"}}}]`},
+			wantTransport: thrift.TransportType_AUTO_TRANSPORT,
+			wantProtocol:  thrift.ProtocolType_AUTO_PROTOCOL,
+			wantFilters:   1,
+		},
+		{
+			name:        "unsupported filter",
+			annotations: map[string]string{filtersAnnotation: `[{"name": "envoy.filters.thrift.router"}]`},
+			wantErr:     true,
+		},
+		{
+			name: "invalid filter config",
+			annotations: map[string]string{filtersAnnotation: `[{"name": "envoy.filters.thrift.rate_limit",
+"typed_config": {"@type": "type.googleapis.com/envoy.extensions.filters.network.thrift_proxy.filters.ratelimit.v3.RateLimit"}}]`},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := parseOptions(c.annotations)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if got.transport != c.wantTransport || got.protocol != c.wantProtocol {
+				t.Errorf("got transport %v protocol %v, want transport %v protocol %v",
+					got.transport, got.protocol, c.wantTransport, c.wantProtocol)
+			}
+			if got.upstream.GetTransport() != c.wantUpstream.GetTransport() ||
+				got.upstream.GetProtocol() != c.wantUpstream.GetProtocol() ||
+				(got.upstream == nil) != (c.wantUpstream == nil) {
+				t.Errorf("got upstream %v, want %v", got.upstream, c.wantUpstream)
+			}
+			if len(got.filters) != c.wantFilters {
+				t.Errorf("got %d filters, want %d", len(got.filters), c.wantFilters)
+			}
+		})
+	}
+}
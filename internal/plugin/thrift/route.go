@@ -15,7 +15,11 @@
 package thrift
 
 import (
+	"fmt"
+
+	routepb "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	thrift "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/thrift_proxy/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	"github.com/golang/protobuf/ptypes/wrappers"
 	networking "istio.io/api/networking/v1alpha3"
 
@@ -39,15 +43,24 @@ func buildOutboundRouteConfig(context *model.EnvoyFilterContext) *thrift.RouteCo
 	}
 }
 
-func buildInboundRouteConfig(context *model.EnvoyFilterContext) *thrift.RouteConfiguration {
+func buildInboundRouteConfig(context *model.EnvoyFilterContext, o *options) *thrift.RouteConfiguration {
 	clusterName := model.BuildClusterName(model.TrafficDirectionInbound, "",
 		context.ServiceEntry.Spec.Hosts[0], int(context.ServiceEntry.Spec.Ports[0].Number))
 
+	route := []*thrift.Route{defaultRoute(clusterName)}
+	setRateLimits(route, o.rateLimits)
+
 	return &thrift.RouteConfiguration{
-		Name: clusterName,
-		Routes: []*thrift.Route{
-			defaultRoute(clusterName),
-		},
+		Name:   clusterName,
+		Routes: route,
+	}
+}
+
+// setRateLimits sets the rate limit actions, which are used by the rate_limit filter to build the descriptors of the
+// requests
+func setRateLimits(routes []*thrift.Route, rateLimits []*routepb.RateLimit) {
+	for _, r := range routes {
+		r.Route.RateLimits = rateLimits
 	}
 }
 
@@ -66,12 +79,17 @@ func defaultRoute(clusterName string) *thrift.Route {
 	}
 }
 
+// buildRoute builds the Thrift routes from the VirtualService, each match block of a http route is translated into a
+// separate Thrift route.
 func buildRoute(context *model.EnvoyFilterContext) []*thrift.Route {
 	service := context.ServiceEntry.Spec
 	vs := context.VirtualService.Spec
 
 	routes := make([]*thrift.Route, 0)
 	for _, http := range vs.Http {
+		if len(http.Route) == 0 {
+			continue
+		}
 		var routeAction *thrift.RouteAction
 
 		if len(http.Route) > 1 {
@@ -80,19 +98,114 @@ func buildRoute(context *model.EnvoyFilterContext) []*thrift.Route {
 			routeAction = buildSingleCluster(http, service)
 		}
 
-		routes = append(routes, &thrift.Route{
-			//todo: convert virtual service HTTP Route Match to Dubbo Route Match
-			Match: &thrift.RouteMatch{
-				MatchSpecifier: &thrift.RouteMatch_MethodName{
-					MethodName: "", // empty string matches any request method name
-				},
-			},
-			Route: routeAction,
-		})
+		matches := http.Match
+		if len(matches) == 0 {
+			matches = []*networking.HTTPMatchRequest{nil}
+		}
+		for _, match := range matches {
+			routeMatch, err := buildRouteMatch(match)
+			if err != nil {
+				generatorLog.Warnf("ignored match of http route %q in virtual service %s.%s: %v",
+					http.Name, context.VirtualService.Namespace, context.VirtualService.Name, err)
+				continue
+			}
+			routes = append(routes, &thrift.Route{
+				Match: routeMatch,
+				Route: routeAction,
+			})
+		}
 	}
 	return routes
 }
 
+// buildRouteMatch translates a http match block into a Thrift route match.
+// The method is matched against the Thrift method name, and the uri is matched against the service name of multiplexed
+// services. Since a Thrift route matches either the method name or the service name, a method match combined with a
+// uri match is only supported when both of them are exact, in which case the multiplexed method name
+// "<service>:<method>" is used.
+func buildRouteMatch(match *networking.HTTPMatchRequest) (*thrift.RouteMatch, error) {
+	routeMatch := &thrift.RouteMatch{
+		MatchSpecifier: &thrift.RouteMatch_MethodName{
+			MethodName: "", // empty string matches any request method name
+		},
+	}
+	if match == nil {
+		return routeMatch, nil
+	}
+
+	var methodName, serviceName string
+	if match.Method != nil {
+		if _, ok := match.Method.MatchType.(*networking.StringMatch_Exact); !ok {
+			return nil, fmt.Errorf("only exact match is supported for thrift method name")
+		}
+		methodName = match.Method.GetExact()
+	}
+	if match.Uri != nil {
+		switch match.Uri.MatchType.(type) {
+		case *networking.StringMatch_Exact:
+			serviceName = match.Uri.GetExact()
+		case *networking.StringMatch_Prefix:
+			if methodName != "" {
+				return nil, fmt.Errorf("uri prefix match can't be used together with method match")
+			}
+			serviceName = match.Uri.GetPrefix()
+		default:
+			return nil, fmt.Errorf("only exact and prefix match are supported for thrift service name")
+		}
+	}
+
+	switch {
+	case methodName != "" && serviceName != "":
+		routeMatch.MatchSpecifier = &thrift.RouteMatch_MethodName{
+			MethodName: serviceName + ":" + methodName,
+		}
+	case methodName != "":
+		routeMatch.MatchSpecifier = &thrift.RouteMatch_MethodName{
+			MethodName: methodName,
+		}
+	case serviceName != "":
+		routeMatch.MatchSpecifier = &thrift.RouteMatch_ServiceName{
+			ServiceName: serviceName,
+		}
+	}
+	routeMatch.Headers = buildHeaderMatch(match)
+	return routeMatch, nil
+}
+
+// buildHeaderMatch builds the header matches, which are matched against the headers of the header transport
+func buildHeaderMatch(match *networking.HTTPMatchRequest) []*routepb.HeaderMatcher {
+	var headerMatchers []*routepb.HeaderMatcher
+	for name, value := range match.Headers {
+		switch value.MatchType.(type) {
+		case *networking.StringMatch_Exact:
+			headerMatchers = append(headerMatchers, &routepb.HeaderMatcher{
+				Name: name,
+				HeaderMatchSpecifier: &routepb.HeaderMatcher_ExactMatch{
+					ExactMatch: value.GetExact(),
+				},
+			})
+		case *networking.StringMatch_Prefix:
+			headerMatchers = append(headerMatchers, &routepb.HeaderMatcher{
+				Name: name,
+				HeaderMatchSpecifier: &routepb.HeaderMatcher_PrefixMatch{
+					PrefixMatch: value.GetPrefix(),
+				},
+			})
+		case *networking.StringMatch_Regex:
+			headerMatchers = append(headerMatchers, &routepb.HeaderMatcher{
+				Name: name,
+				HeaderMatchSpecifier: &routepb.HeaderMatcher_SafeRegexMatch{
+					SafeRegexMatch: &matcher.RegexMatcher{
+						EngineType: &matcher.RegexMatcher_GoogleRe2{GoogleRe2: &matcher.RegexMatcher_GoogleRE2{}},
+						Regex:      value.GetRegex(),
+					},
+				},
+			})
+		}
+	}
+	return headerMatchers
+}
+
 func buildSingleCluster(http *networking.HTTPRoute, service *networking.ServiceEntry) *thrift.RouteAction {
 	clusterName := model.BuildClusterName(model.TrafficDirectionOutbound, http.Route[0].Destination.Subset,
 		service.Hosts[0], int(service.Ports[0].Number))
@@ -0,0 +1,92 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package thrift
+
+import (
+	"testing"
+
+	routepb "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	thrift "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/thrift_proxy/v3"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+func TestBuildRouteMatch(t *testing.T) {
+	exact := func(value string) *networking.StringMatch {
+		return &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: value}}
+	}
+	prefix := func(value string) *networking.StringMatch {
+		return &networking.StringMatch{MatchType: &networking.StringMatch_Prefix{Prefix: value}}
+	}
+	cases := []struct {
+		name    string
+		match   *networking.HTTPMatchRequest
+		want    *thrift.RouteMatch
+		wantErr bool
+	}{
+		{
+			name:  "no match",
+			match: nil,
+			want:  &thrift.RouteMatch{MatchSpecifier: &thrift.RouteMatch_MethodName{}},
+		},
+		{
+			name: "method and header",
+			match: &networking.HTTPMatchRequest{
+				Method:  exact("sayHello"),
+				Headers: map[string]*networking.StringMatch{"version": exact("v1")},
+			},
+			want: &thrift.RouteMatch{
+				MatchSpecifier: &thrift.RouteMatch_MethodName{MethodName: "sayHello"},
+				Headers: []*routepb.HeaderMatcher{{
+					Name:                 "version",
+					HeaderMatchSpecifier: &routepb.HeaderMatcher_ExactMatch{ExactMatch: "v1"},
+				}},
+			},
+		},
+		{
+			name:  "service name",
+			match: &networking.HTTPMatchRequest{Uri: prefix("Hello")},
+			want:  &thrift.RouteMatch{MatchSpecifier: &thrift.RouteMatch_ServiceName{ServiceName: "Hello"}},
+		},
+		{
+			name:  "multiplexed method name",
+			match: &networking.HTTPMatchRequest{Uri: exact("Hello"), Method: exact("sayHello")},
+			want:  &thrift.RouteMatch{MatchSpecifier: &thrift.RouteMatch_MethodName{MethodName: "Hello:sayHello"}},
+		},
+		{
+			name:    "method prefix",
+			match:   &networking.HTTPMatchRequest{Method: prefix("say")},
+			wantErr: true,
+		},
+		{
+			name:    "uri prefix with method",
+			match:   &networking.HTTPMatchRequest{Uri: prefix("Hello"), Method: exact("sayHello")},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := buildRouteMatch(c.match)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(c.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected route match (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
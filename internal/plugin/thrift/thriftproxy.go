@@ -20,29 +20,35 @@ import (
 	"github.com/aeraki-mesh/aeraki/internal/model"
 )
 
-func buildOutboundProxy(context *model.EnvoyFilterContext) *thrift.ThriftProxy {
-	route := buildOutboundRouteConfig(context)
-
-	return newThriftProxy(context, route, model.TrafficDirectionOutbound)
+func buildOutboundProxy(context *model.EnvoyFilterContext, o *options) *thrift.ThriftProxy {
+	proxy := newThriftProxy(context, model.TrafficDirectionOutbound, o)
+	proxy.RouteConfig = buildOutboundRouteConfig(context)
+	return proxy
 }
 
-func buildInboundProxy(context *model.EnvoyFilterContext) *thrift.ThriftProxy {
-	route := buildInboundRouteConfig(context)
-	return newThriftProxy(context, route, model.TrafficDirectionInbound)
+func buildInboundProxy(context *model.EnvoyFilterContext, o *options) *thrift.ThriftProxy {
+	proxy := newThriftProxy(context, model.TrafficDirectionInbound, o)
+	proxy.RouteConfig = buildInboundRouteConfig(context, o)
+	return proxy
 }
 
-func newThriftProxy(context *model.EnvoyFilterContext, route *thrift.RouteConfiguration,
-	trafficDirection model.TrafficDirection) *thrift.ThriftProxy {
+// newThriftProxy creates a Thrift proxy, the filters in the annotation are only inserted into the inbound proxy, so
+// that the requests are not rate limited twice by the client and server sidecars
+func newThriftProxy(context *model.EnvoyFilterContext, trafficDirection model.TrafficDirection,
+	o *options) *thrift.ThriftProxy {
+	var filters []*thrift.ThriftFilter
+	if trafficDirection == model.TrafficDirectionInbound {
+		filters = append(filters, o.filters...)
+	}
+	filters = append(filters, &thrift.ThriftFilter{
+		Name: "envoy.filters.thrift.router",
+	})
+
 	return &thrift.ThriftProxy{
 		StatPrefix: model.BuildClusterName(trafficDirection, "",
 			context.ServiceEntry.Spec.Hosts[0], int(context.ServiceEntry.Spec.Ports[0].Number)),
-		Transport:   thrift.TransportType_AUTO_TRANSPORT,
-		Protocol:    thrift.ProtocolType_AUTO_PROTOCOL,
-		RouteConfig: route,
-		ThriftFilters: []*thrift.ThriftFilter{
-			{
-				Name: "envoy.filters.thrift.router",
-			},
-		},
+		Transport:     o.transport,
+		Protocol:      o.protocol,
+		ThriftFilters: filters,
 	}
 }
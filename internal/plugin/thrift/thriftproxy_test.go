@@ -0,0 +1,66 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package thrift
+
+import (
+	"testing"
+
+	routepb "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	thrift "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/thrift_proxy/v3"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"github.com/aeraki-mesh/aeraki/internal/model"
+)
+
+func TestBuildProxyFiltersInboundOnly(t *testing.T) {
+	context := &model.EnvoyFilterContext{
+		ServiceEntry: &model.ServiceEntryWrapper{
+			Spec: &networking.ServiceEntry{
+				Hosts: []string{"hello.thrift.svc.cluster.local"},
+				Ports: []*networking.ServicePort{{Number: 9090, Name: "tcp-thrift-hello"}},
+			},
+		},
+	}
+	o := &options{
+		filters: []*thrift.ThriftFilter{{Name: "envoy.filters.thrift.rate_limit"}},
+		rateLimits: []*routepb.RateLimit{{
+			Actions: []*routepb.RateLimit_Action{{
+				ActionSpecifier: &routepb.RateLimit_Action_SourceCluster_{
+					SourceCluster: &routepb.RateLimit_Action_SourceCluster{},
+				},
+			}},
+		}},
+	}
+
+	outbound := buildOutboundProxy(context, o)
+	if len(outbound.ThriftFilters) != 1 || outbound.ThriftFilters[0].Name != "envoy.filters.thrift.router" {
+		t.Errorf("outbound proxy should only have the router filter, got %v", outbound.ThriftFilters)
+	}
+	for _, r := range outbound.RouteConfig.Routes {
+		if len(r.Route.RateLimits) != 0 {
+			t.Errorf("outbound route should not have rate limits, got %v", r.Route.RateLimits)
+		}
+	}
+
+	inbound := buildInboundProxy(context, o)
+	if len(inbound.ThriftFilters) != 2 || inbound.ThriftFilters[0].Name != "envoy.filters.thrift.rate_limit" {
+		t.Errorf("inbound proxy should have the rate limit filter before the router, got %v", inbound.ThriftFilters)
+	}
+	for _, r := range inbound.RouteConfig.Routes {
+		if len(r.Route.RateLimits) != 1 {
+			t.Errorf("inbound route should have the rate limits, got %v", r.Route.RateLimits)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	nacosServiceListPath  = "/nacos/v1/ns/service/list"
+	nacosInstanceListPath = "/nacos/v1/ns/instance/list"
+	nacosPageSize         = 500
+	nacosTimeout          = 10 * time.Second
+
+	// dubboProviderPrefix is the prefix of the service names of Dubbo providers in Nacos, a provider is registered as
+	// "providers:<interface>:<version>:<group>"
+	dubboProviderPrefix = "providers:"
+)
+
+// NacosRegistry reads Dubbo providers from a Nacos registry through the Nacos open API
+type NacosRegistry struct {
+	name        string
+	addr        string
+	namespaceID string
+	group       string
+	client      *http.Client
+}
+
+// NewNacosRegistry creates a NacosRegistry, addr is the address of the Nacos server, e.g. http://nacos:8848
+func NewNacosRegistry(name, addr, namespaceID, group string) *NacosRegistry {
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "http://" + addr
+	}
+	return &NacosRegistry{
+		name:        name,
+		addr:        strings.TrimSuffix(addr, "/"),
+		namespaceID: namespaceID,
+		group:       group,
+		client:      &http.Client{Timeout: nacosTimeout},
+	}
+}
+
+// Name returns the name of the registry
+func (r *NacosRegistry) Name() string {
+	return r.name
+}
+
+type nacosServiceList struct {
+	Count int      `json:"count"`
+	Doms  []string `json:"doms"`
+}
+
+type nacosInstanceList struct {
+	Hosts []nacosInstance `json:"hosts"`
+}
+
+type nacosInstance struct {
+	IP       string            `json:"ip"`
+	Port     uint32            `json:"port"`
+	Healthy  bool              `json:"healthy"`
+	Enabled  bool              `json:"enabled"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// Providers returns all the healthy Dubbo provider instances in the registry
+func (r *NacosRegistry) Providers(ctx context.Context) ([]Provider, error) {
+	services, err := r.listServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var providers []Provider
+	for _, service := range services {
+		iface, version, group := parseDubboProviderName(service)
+		if iface == "" {
+			continue
+		}
+		instances := &nacosInstanceList{}
+		if err := r.get(ctx, nacosInstanceListPath, url.Values{
+			"serviceName": {service},
+			"healthyOnly": {"true"},
+		}, instances); err != nil {
+			return nil, err
+		}
+		for _, instance := range instances.Hosts {
+			if !instance.Healthy || !instance.Enabled {
+				continue
+			}
+			labels := map[string]string{}
+			for k, v := range map[string]string{
+				"version":     version,
+				"group":       group,
+				"application": instance.Metadata["application"],
+			} {
+				if v != "" && len(validation.IsValidLabelValue(v)) == 0 {
+					labels[k] = v
+				}
+			}
+			providers = append(providers, Provider{
+				Interface: iface,
+				Address:   instance.IP,
+				Port:      instance.Port,
+				Labels:    labels,
+			})
+		}
+	}
+	return providers, nil
+}
+
+func (r *NacosRegistry) listServices(ctx context.Context) ([]string, error) {
+	var services []string
+	for page := 1; ; page++ {
+		list := &nacosServiceList{}
+		if err := r.get(ctx, nacosServiceListPath, url.Values{
+			"pageNo":   {strconv.Itoa(page)},
+			"pageSize": {strconv.Itoa(nacosPageSize)},
+		}, list); err != nil {
+			return nil, err
+		}
+		for _, service := range list.Doms {
+			if strings.HasPrefix(service, dubboProviderPrefix) {
+				services = append(services, service)
+			}
+		}
+		if len(list.Doms) < nacosPageSize || page*nacosPageSize >= list.Count {
+			return services, nil
+		}
+	}
+}
+
+func (r *NacosRegistry) get(ctx context.Context, path string, params url.Values, result interface{}) error {
+	if r.namespaceID != "" {
+		params.Set("namespaceId", r.namespaceID)
+	}
+	if r.group != "" {
+		params.Set("groupName", r.group)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.addr+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to request nacos %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// parseDubboProviderName parses a Dubbo provider name in the format of "providers:<interface>:<version>:<group>"
+func parseDubboProviderName(name string) (iface, version, group string) {
+	parts := strings.Split(strings.TrimPrefix(name, dubboProviderPrefix), ":")
+	iface = parts[0]
+	if len(parts) > 1 {
+		version = parts[1]
+	}
+	if len(parts) > 2 {
+		group = parts[2]
+	}
+	return iface, version, group
+}
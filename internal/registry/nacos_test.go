@@ -0,0 +1,70 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestNacosRegistry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp interface{}
+		switch r.URL.Path {
+		case nacosServiceListPath:
+			resp = nacosServiceList{
+				Count: 2,
+				Doms:  []string{"providers:org.apache.dubbo.DemoService:1.0.0:", "consumers:org.apache.dubbo.DemoService::"},
+			}
+		case nacosInstanceListPath:
+			if r.URL.Query().Get("serviceName") != "providers:org.apache.dubbo.DemoService:1.0.0:" {
+				t.Errorf("unexpected service name: %s", r.URL.Query().Get("serviceName"))
+			}
+			resp = nacosInstanceList{
+				Hosts: []nacosInstance{
+					{IP: "10.0.0.1", Port: 20880, Healthy: true, Enabled: true,
+						Metadata: map[string]string{"application": "demo"}},
+					{IP: "10.0.0.2", Port: 20880, Healthy: false, Enabled: true},
+				},
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	registry := NewNacosRegistry("nacos", server.URL, "", "")
+	providers, err := registry.Providers(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Provider{
+		{
+			Interface: "org.apache.dubbo.DemoService",
+			Address:   "10.0.0.1",
+			Port:      20880,
+			Labels:    map[string]string{"version": "1.0.0", "application": "demo"},
+		},
+	}
+	if !reflect.DeepEqual(providers, want) {
+		t.Errorf("want %v, got %v", want, providers)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package registry synchronizes the Dubbo providers registered in a service registry to ServiceEntries, so the Dubbo
+// services can be managed by Aeraki without manually maintained ServiceEntries.
+package registry
+
+import (
+	"context"
+
+	"istio.io/pkg/log"
+)
+
+var registryLog = log.RegisterScope("registry", "dubbo registry synchronization", 0)
+
+// Provider is a provider instance of a Dubbo interface registered in a service registry
+type Provider struct {
+	// Interface is the Dubbo interface, e.g. org.apache.dubbo.samples.basic.api.DemoService
+	Interface string
+	// Address is the IP address of the provider instance
+	Address string
+	// Port is the port which the provider instance listens on
+	Port uint32
+	// Labels are the labels of the provider instance, such as version and group
+	Labels map[string]string
+}
+
+// Registry is a Dubbo service registry, such as Nacos
+type Registry interface {
+	// Name is the name of the registry, the ServiceEntries created for a registry are labeled with its name
+	Name() string
+	// Providers returns all the provider instances in the registry
+	Providers(ctx context.Context) ([]Provider, error)
+}
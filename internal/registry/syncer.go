@@ -0,0 +1,227 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	istioapi "istio.io/api/networking/v1alpha3"
+	networking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	controllerclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aeraki-mesh/aeraki/internal/config/constants"
+)
+
+const (
+	// managerLabel marks the ServiceEntries managed by the registry syncer
+	managerLabel = "manager"
+	managerValue = "aeraki-registry"
+	// registryLabel is the name of the registry which a ServiceEntry is synchronized from
+	registryLabel = "registry"
+	// interfaceAnnotation is used by the Dubbo generator to build the routes of a Dubbo service
+	interfaceAnnotation = "interface"
+
+	defaultSyncInterval = 30 * time.Second
+	// emptySyncThreshold is the number of consecutive syncs in which the registry returns no provider before the
+	// managed ServiceEntries are deleted. A registry may return an empty list when it's restarting or misconfigured,
+	// deleting all the ServiceEntries right away would break all the Dubbo services in the mesh.
+	emptySyncThreshold = 3
+)
+
+// Syncer synchronizes the providers in a Dubbo registry to ServiceEntries.
+// A ServiceEntry is created for each Dubbo interface, named and hosted by the lower-cased interface name, with the
+// provider instances as its endpoints. The ServiceEntries of the interfaces which no longer have providers are
+// deleted.
+type Syncer struct {
+	registry Registry
+	client   controllerclient.Client
+	// namespace is where the ServiceEntries are created
+	namespace string
+	// portName is the name of the ServiceEntry port, tcp-dubbo or tcp-metaprotocol-dubbo
+	portName string
+	interval time.Duration
+	// emptySyncs is the number of consecutive syncs in which the registry returns no provider
+	emptySyncs int
+}
+
+// NewSyncer creates a Syncer which synchronizes the providers in the registry to the ServiceEntries in the namespace
+func NewSyncer(registry Registry, client controllerclient.Client, namespace, portName string) *Syncer {
+	return &Syncer{
+		registry:  registry,
+		client:    client,
+		namespace: namespace,
+		portName:  portName,
+		interval:  defaultSyncInterval,
+	}
+}
+
+// Start synchronizes the registry periodically until the context is done, it implements manager.Runnable so the
+// syncer can be run by a leader-elected controller manager.
+func (s *Syncer) Start(ctx context.Context) error {
+	registryLog.Infof("start synchronizing dubbo registry %s to namespace %s", s.registry.Name(), s.namespace)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Sync(ctx); err != nil {
+			registryLog.Errorf("failed to synchronize dubbo registry %s: %v", s.registry.Name(), err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Sync synchronizes the providers in the registry to ServiceEntries once
+func (s *Syncer) Sync(ctx context.Context) error {
+	providers, err := s.registry.Providers(ctx)
+	if err != nil {
+		return err
+	}
+	desired := s.buildServiceEntries(providers)
+
+	existing := &networking.ServiceEntryList{}
+	if err := s.client.List(ctx, existing, controllerclient.InNamespace(s.namespace),
+		controllerclient.MatchingLabels{managerLabel: managerValue, registryLabel: s.registry.Name()}); err != nil {
+		return err
+	}
+
+	if len(desired) == 0 && len(existing.Items) > 0 {
+		s.emptySyncs++
+		if s.emptySyncs < emptySyncThreshold {
+			registryLog.Warnf("no provider found in registry %s, keep the %d ServiceEntries until it's confirmed by "+
+				"%d consecutive syncs", s.registry.Name(), len(existing.Items), emptySyncThreshold)
+			return nil
+		}
+	} else {
+		s.emptySyncs = 0
+	}
+
+	for _, se := range desired {
+		if err := s.createOrUpdate(ctx, se); err != nil {
+			registryLog.Errorf("failed to synchronize ServiceEntry %s: %v", se.Name, err)
+		}
+	}
+
+	// garbage-collect the ServiceEntries of the interfaces which no longer have providers
+	for i := range existing.Items {
+		se := existing.Items[i]
+		if _, ok := desired[se.Name]; ok {
+			continue
+		}
+		registryLog.Infof("delete ServiceEntry %s/%s, no provider found in the registry", se.Namespace, se.Name)
+		if err := s.client.Delete(ctx, se); err != nil && !errors.IsNotFound(err) {
+			registryLog.Errorf("failed to delete ServiceEntry %s: %v", se.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *Syncer) createOrUpdate(ctx context.Context, se *networking.ServiceEntry) error {
+	current := &networking.ServiceEntry{}
+	err := s.client.Get(ctx, controllerclient.ObjectKeyFromObject(se), current)
+	if errors.IsNotFound(err) {
+		registryLog.Infof("create ServiceEntry %s/%s", se.Namespace, se.Name)
+		return s.client.Create(ctx, se, &controllerclient.CreateOptions{
+			FieldManager: constants.AerakiFieldManager,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if current.Labels[managerLabel] != managerValue {
+		registryLog.Warnf("ServiceEntry %s/%s is not managed by registry %s, skip it", se.Namespace, se.Name,
+			s.registry.Name())
+		return nil
+	}
+	// the VIP may have been allocated by the service entry controller, keep it
+	se.Spec.Addresses = current.Spec.Addresses
+	if proto.Equal(&current.Spec, &se.Spec) &&
+		current.Annotations[interfaceAnnotation] == se.Annotations[interfaceAnnotation] {
+		return nil
+	}
+	se.Spec.DeepCopyInto(&current.Spec)
+	if current.Annotations == nil {
+		current.Annotations = map[string]string{}
+	}
+	current.Annotations[interfaceAnnotation] = se.Annotations[interfaceAnnotation]
+	registryLog.Infof("update ServiceEntry %s/%s", se.Namespace, se.Name)
+	return s.client.Update(ctx, current, &controllerclient.UpdateOptions{
+		FieldManager: constants.AerakiFieldManager,
+	})
+}
+
+func (s *Syncer) buildServiceEntries(providers []Provider) map[string]*networking.ServiceEntry {
+	interfaces := map[string][]Provider{}
+	for _, provider := range providers {
+		if provider.Interface == "" || provider.Address == "" || provider.Port == 0 {
+			continue
+		}
+		interfaces[provider.Interface] = append(interfaces[provider.Interface], provider)
+	}
+
+	serviceEntries := map[string]*networking.ServiceEntry{}
+	for iface, instances := range interfaces {
+		// keep the endpoints in a stable order to avoid unnecessary updates
+		sort.Slice(instances, func(i, j int) bool {
+			if instances[i].Address != instances[j].Address {
+				return instances[i].Address < instances[j].Address
+			}
+			return instances[i].Port < instances[j].Port
+		})
+		name := strings.ToLower(iface)
+		se := &networking.ServiceEntry{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      name,
+				Namespace: s.namespace,
+				Labels: map[string]string{
+					managerLabel:  managerValue,
+					registryLabel: s.registry.Name(),
+				},
+				Annotations: map[string]string{
+					interfaceAnnotation: iface,
+				},
+			},
+			Spec: istioapi.ServiceEntry{
+				Hosts: []string{name},
+				Ports: []*istioapi.ServicePort{
+					{
+						Name:     s.portName,
+						Number:   instances[0].Port,
+						Protocol: "TCP",
+					},
+				},
+				Location:   istioapi.ServiceEntry_MESH_INTERNAL,
+				Resolution: istioapi.ServiceEntry_STATIC,
+			},
+		}
+		for _, instance := range instances {
+			se.Spec.Endpoints = append(se.Spec.Endpoints, &istioapi.WorkloadEntry{
+				Address: instance.Address,
+				Ports:   map[string]uint32{s.portName: instance.Port},
+				Labels:  instance.Labels,
+			})
+		}
+		serviceEntries[name] = se
+	}
+	return serviceEntries
+}
@@ -0,0 +1,150 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"testing"
+
+	networking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	controllerclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeRegistry is an in-process registry for testing
+type fakeRegistry struct {
+	providers []Provider
+}
+
+func (r *fakeRegistry) Name() string {
+	return "fake"
+}
+
+func (r *fakeRegistry) Providers(_ context.Context) ([]Provider, error) {
+	return r.providers, nil
+}
+
+func TestSyncer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := networking.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	unmanaged := &networking.ServiceEntry{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "org.apache.dubbo.unmanaged",
+			Namespace: "dubbo",
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(unmanaged).Build()
+	registry := &fakeRegistry{
+		providers: []Provider{
+			{
+				Interface: "org.apache.dubbo.DemoService",
+				Address:   "10.0.0.2",
+				Port:      20880,
+				Labels:    map[string]string{"version": "v2"},
+			},
+			{
+				Interface: "org.apache.dubbo.DemoService",
+				Address:   "10.0.0.1",
+				Port:      20880,
+				Labels:    map[string]string{"version": "v1"},
+			},
+			{
+				Interface: "org.apache.dubbo.HelloService",
+				Address:   "10.0.0.3",
+				Port:      20881,
+			},
+			{
+				Interface: "org.apache.dubbo.unmanaged",
+				Address:   "10.0.0.4",
+				Port:      20880,
+			},
+		},
+	}
+	syncer := NewSyncer(registry, client, "dubbo", "tcp-metaprotocol-dubbo")
+	ctx := context.TODO()
+
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	se := &networking.ServiceEntry{}
+	key := controllerclient.ObjectKey{Namespace: "dubbo", Name: "org.apache.dubbo.demoservice"}
+	if err := client.Get(ctx, key, se); err != nil {
+		t.Fatal(err)
+	}
+	if se.Annotations["interface"] != "org.apache.dubbo.DemoService" {
+		t.Errorf("want interface annotation org.apache.dubbo.DemoService, got %s", se.Annotations["interface"])
+	}
+	if len(se.Spec.Endpoints) != 2 || se.Spec.Endpoints[0].Address != "10.0.0.1" ||
+		se.Spec.Endpoints[0].Labels["version"] != "v1" {
+		t.Errorf("unexpected endpoints: %v", se.Spec.Endpoints)
+	}
+	if se.Spec.Ports[0].Name != "tcp-metaprotocol-dubbo" || se.Spec.Ports[0].Number != 20880 {
+		t.Errorf("unexpected ports: %v", se.Spec.Ports)
+	}
+	if err := client.Get(ctx, controllerclient.ObjectKeyFromObject(unmanaged), se); err != nil {
+		t.Fatal(err)
+	}
+	if len(se.Spec.Endpoints) != 0 {
+		t.Errorf("unmanaged ServiceEntry should not be updated")
+	}
+
+	// the providers of HelloService disappear
+	registry.providers = registry.providers[:2]
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	key = controllerclient.ObjectKey{Namespace: "dubbo", Name: "org.apache.dubbo.helloservice"}
+	if err := client.Get(ctx, key, se); !errors.IsNotFound(err) {
+		t.Errorf("want ServiceEntry %s to be deleted, got %v", key, err)
+	}
+	if err := client.Get(ctx, controllerclient.ObjectKeyFromObject(unmanaged), se); err != nil {
+		t.Errorf("unmanaged ServiceEntry should not be deleted: %v", err)
+	}
+
+	// the registry returns no provider, the ServiceEntries are kept until it's confirmed by consecutive syncs
+	providers := registry.providers
+	registry.providers = nil
+	key = controllerclient.ObjectKey{Namespace: "dubbo", Name: "org.apache.dubbo.demoservice"}
+	for i := 1; i < emptySyncThreshold; i++ {
+		if err := syncer.Sync(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := client.Get(ctx, key, se); err != nil {
+			t.Fatalf("ServiceEntry %s should be kept after %d empty syncs: %v", key, i, err)
+		}
+	}
+	// the providers come back before the threshold is reached
+	registry.providers = providers
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	registry.providers = nil
+	for i := 0; i < emptySyncThreshold; i++ {
+		if err := client.Get(ctx, key, se); err != nil {
+			t.Fatalf("ServiceEntry %s should be kept after %d empty syncs: %v", key, i, err)
+		}
+		if err := syncer.Sync(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.Get(ctx, key, se); !errors.IsNotFound(err) {
+		t.Errorf("want ServiceEntry %s to be deleted, got %v", key, err)
+	}
+}
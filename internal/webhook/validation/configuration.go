@@ -40,6 +40,9 @@ func GenerateWebhookConfig(caCert *bytes.Buffer, namespace string) error {
 
 	path := "/validate"
 	fail := admissionregistrationv1.Fail
+	// ServiceEntries and Redis resources are not blocked if Aeraki is unavailable, their annotations are also checked
+	// by the generators
+	ignore := admissionregistrationv1.Ignore
 
 	sideEffect := admissionregistrationv1.SideEffectClassNone
 	config := &admissionregistrationv1.ValidatingWebhookConfiguration{
@@ -67,6 +70,34 @@ func GenerateWebhookConfig(caCert *bytes.Buffer, namespace string) error {
 			FailurePolicy:           &fail,
 			SideEffects:             &sideEffect,
 			AdmissionReviewVersions: []string{"v1", "v1beta1"},
+		}, {
+			Name: "annotations.validation.aeraki.io",
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				CABundle: caCert.Bytes(),
+				Service: &admissionregistrationv1.ServiceReference{
+					Name:      webhookService,
+					Namespace: namespace,
+					Path:      &path,
+				},
+			},
+			Rules: []admissionregistrationv1.RuleWithOperations{{Operations: []admissionregistrationv1.OperationType{
+				admissionregistrationv1.Create, admissionregistrationv1.Update},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   []string{"networking.istio.io"},
+					APIVersions: []string{"*"},
+					Resources:   []string{"serviceentries"},
+				},
+			}, {Operations: []admissionregistrationv1.OperationType{
+				admissionregistrationv1.Create, admissionregistrationv1.Update},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   []string{"redis.aeraki.io"},
+					APIVersions: []string{"*"},
+					Resources:   []string{"redisservices", "redisdestinations"},
+				},
+			}},
+			FailurePolicy:           &ignore,
+			SideEffects:             &sideEffect,
+			AdmissionReviewVersions: []string{"v1", "v1beta1"},
 		}},
 	}
 
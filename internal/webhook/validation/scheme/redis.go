@@ -0,0 +1,29 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheme
+
+import (
+	"github.com/aeraki-mesh/aeraki/internal/model"
+	"github.com/aeraki-mesh/aeraki/internal/plugin/redis"
+)
+
+// ValidateRedisAnnotations validates the annotations which configure the Redis generator of a RedisService or a
+// RedisDestination.
+// The spec of these resources is validated by the schema of their CRDs.
+func ValidateRedisAnnotations(annotations map[string]string) (errs error) {
+	errs = appendErrors(errs, model.ValidateRedisDownstreamAuthSecrets(annotations))
+	errs = appendErrors(errs, redis.ValidateAnnotations(annotations))
+	return errs
+}
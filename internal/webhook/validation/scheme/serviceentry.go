@@ -0,0 +1,30 @@
+// Copyright Aeraki Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheme
+
+import (
+	"github.com/aeraki-mesh/aeraki/internal/model"
+	"github.com/aeraki-mesh/aeraki/internal/plugin/kafka"
+	"github.com/aeraki-mesh/aeraki/internal/plugin/thrift"
+)
+
+// ValidateServiceEntryAnnotations validates the annotations which configure the Aeraki generators of a ServiceEntry.
+// The spec of a ServiceEntry is validated by the Istio webhook.
+func ValidateServiceEntryAnnotations(annotations map[string]string) (errs error) {
+	errs = appendErrors(errs, thrift.ValidateAnnotations(annotations))
+	errs = appendErrors(errs, model.ValidateDubboSerialization(annotations))
+	errs = appendErrors(errs, kafka.ValidateAnnotations(annotations))
+	return errs
+}
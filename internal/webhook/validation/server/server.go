@@ -34,6 +34,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	"github.com/aeraki-mesh/aeraki/internal/webhook/validation/scheme"
 )
 
 var scope = log.RegisterScope("aerakiValidationServer", "aeraki validation webhook server", 0)
@@ -205,6 +207,29 @@ func (wh *Webhook) validate(request *kube.AdmissionRequest) *kube.AdmissionRespo
 
 	gvk := obj.GroupVersionKind()
 
+	// the spec of a ServiceEntry is validated by the Istio webhook, only the Aeraki annotations are validated here
+	if gvk.Group == "networking.istio.io" && gvk.Kind == "ServiceEntry" {
+		if err := scheme.ValidateServiceEntryAnnotations(obj.Annotations); err != nil {
+			scope.Infof("configuration is invalid: %v", err)
+			reportValidationFailed(request, reasonInvalidConfig)
+			return toAdmissionResponse(fmt.Errorf("configuration is invalid: %v", err))
+		}
+		reportValidationPass(request)
+		return &kube.AdmissionResponse{Allowed: true}
+	}
+
+	// the spec of a RedisService or a RedisDestination is validated by its CRD schema, only the Aeraki annotations
+	// are validated here
+	if gvk.Group == "redis.aeraki.io" {
+		if err := scheme.ValidateRedisAnnotations(obj.Annotations); err != nil {
+			scope.Infof("configuration is invalid: %v", err)
+			reportValidationFailed(request, reasonInvalidConfig)
+			return toAdmissionResponse(fmt.Errorf("configuration is invalid: %v", err))
+		}
+		reportValidationPass(request)
+		return &kube.AdmissionResponse{Allowed: true}
+	}
+
 	// TODO(jasonwzm) remove this when multi-version is supported. v1beta1 shares the same
 	// schema as v1lalpha3. Fake conversion and validate against v1alpha3.
 	// if gvk.Group == "networking.istio.io" && gvk.Version == "v1beta1" {
@@ -28,12 +28,14 @@ import (
 	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/zhaohuabing/debounce"
+	"google.golang.org/protobuf/proto"
 	networking "istio.io/api/networking/v1alpha3"
 	istiomodel "istio.io/istio/pilot/pkg/model"
 	istioconfig "istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/config/schema/gvk"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/aeraki-mesh/aeraki/internal/config/constants"
 	"github.com/aeraki-mesh/aeraki/internal/model"
 	"github.com/aeraki-mesh/aeraki/internal/model/protocol"
 )
@@ -47,6 +49,10 @@ const (
 	// debounceMax is the maximum time to wait for events while debouncing.
 	// Defaults to 10 seconds. If events keep showing up with no break for this time, we'll trigger a push.
 	debounceMax = 10 * time.Second
+
+	// dynamicSubsetAnnotation is the MetaRouter annotation which routes the requests to the subsets named by the value
+	// of a request metadata
+	dynamicSubsetAnnotation = "metaprotocol.aeraki.io/dynamicSubset"
 )
 
 // CacheMgr contains the runtime configuration for the envoyFilter controller.
@@ -208,8 +214,9 @@ func (c *CacheMgr) constructRoute(service *networking.ServiceEntry,
 	port *networking.ServicePort, metaRouter *metaprotocol.MetaRouter, dr *model.DestinationRuleWrapper) *metaroute.
 	RouteConfiguration {
 	var routes []*metaroute.Route
+	dynamicRoutesAdded := false
 	for _, route := range metaRouter.Spec.Routes {
-		routes = append(routes, &metaroute.Route{
+		metaRoute := &metaroute.Route{
 			Name: route.Name,
 			Match: &metaroute.RouteMatch{
 				Metadata: MetaMatch2HttpHeaderMatch(route.Match),
@@ -217,7 +224,16 @@ func (c *CacheMgr) constructRoute(service *networking.ServiceEntry,
 			Route:            c.constructAction(port, route, dr),
 			RequestMutation:  c.constructMutation(route.RequestMutation),
 			ResponseMutation: c.constructMutation(route.ResponseMutation),
-		})
+		}
+		// the dynamic routes are inserted before the first route without match conditions, which is the fallback
+		if !dynamicRoutesAdded && len(metaRoute.Match.Metadata) == 0 {
+			routes = append(routes, c.constructDynamicRoutes(service, port, metaRouter, dr, metaRoute)...)
+			dynamicRoutesAdded = true
+		}
+		routes = append(routes, metaRoute)
+	}
+	if !dynamicRoutesAdded {
+		routes = append(routes, c.constructDynamicRoutes(service, port, metaRouter, dr, nil)...)
 	}
 	// Currently, the routes for different port are the same, but we may need different routes for different ports in
 	// the future
@@ -228,6 +244,70 @@ func (c *CacheMgr) constructRoute(service *networking.ServiceEntry,
 	return &metaRoute
 }
 
+// constructDynamicRoutes generates the routes which pick a subset from the value of a request metadata, according to
+// the dynamic subset annotation of the MetaRouter. The annotation value is in the format of
+// "<metadata key>=<subset prefix>", e.g. "x-cell=cell-" routes a request with "x-cell: a" to subset "cell-a".
+// A route is generated for each subset of the DestinationRule which has the prefix. These routes are placed after the
+// routes with match conditions in the MetaRouter and before the fallback route, which is used when the metadata value
+// is missing or unknown. The dynamic routes keep the mirror policies and mutations of the fallback route, only the
+// cluster and the hash policy are replaced.
+func (c *CacheMgr) constructDynamicRoutes(service *networking.ServiceEntry, port *networking.ServicePort,
+	metaRouter *metaprotocol.MetaRouter, dr *model.DestinationRuleWrapper,
+	fallback *metaroute.Route) []*metaroute.Route {
+	annotation, exist := metaRouter.Annotations[dynamicSubsetAnnotation]
+	if !exist {
+		return nil
+	}
+	key, prefix, found := strings.Cut(strings.TrimSpace(annotation), "=")
+	key = strings.TrimSpace(key)
+	if !found || key == "" {
+		xdsLog.Errorf("invalid %s annotation of meta router %s: %s", dynamicSubsetAnnotation, metaRouter.Name,
+			annotation)
+		return nil
+	}
+	if dr == nil {
+		xdsLog.Warnf("no destination rule found for %s of meta router %s", dynamicSubsetAnnotation, metaRouter.Name)
+		return nil
+	}
+	prefix = strings.TrimSpace(prefix)
+
+	var routes []*metaroute.Route
+	for _, subset := range dr.Spec.Subsets {
+		value := strings.TrimPrefix(subset.Name, prefix)
+		if value == "" || (prefix != "" && !strings.HasPrefix(subset.Name, prefix)) {
+			continue
+		}
+		route := &metaroute.Route{Route: &metaroute.RouteAction{}}
+		if fallback != nil {
+			route = proto.Clone(fallback).(*metaroute.Route)
+			if route.Route == nil {
+				route.Route = &metaroute.RouteAction{}
+			}
+		}
+		route.Name = "dynamic-" + subset.Name
+		route.Match = &metaroute.RouteMatch{
+			Metadata: []*routev3.HeaderMatcher{
+				{
+					Name: key,
+					HeaderMatchSpecifier: &routev3.HeaderMatcher_ExactMatch{
+						ExactMatch: value,
+					},
+				},
+			},
+		}
+		route.Route.ClusterSpecifier = &metaroute.RouteAction_Cluster{
+			Cluster: model.BuildClusterName(model.TrafficDirectionOutbound, subset.Name,
+				service.Hosts[0], int(port.Number)),
+		}
+		route.Route.HashPolicy = nil
+		if policy := model.GetHashPolicy(dr, subset.Name); policy != "" {
+			route.Route.HashPolicy = []string{policy}
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
 func (c *CacheMgr) constructAction(port *networking.ServicePort,
 	route *metaprotocolapi.MetaRoute, dr *model.DestinationRuleWrapper) *metaroute.RouteAction {
 	var routeAction = &metaroute.RouteAction{}
@@ -354,7 +434,8 @@ func (c *CacheMgr) findRelatedServiceEntry(dr *model.DestinationRuleWrapper) (*m
 
 func (c *CacheMgr) findRelatedMetaRouter(service *networking.ServiceEntry) (*metaprotocol.MetaRouter, error) {
 	metaRouterList := metaprotocol.MetaRouterList{}
-	err := c.MetaRouterControllerClient.List(context.TODO(), &metaRouterList, &client.ListOptions{})
+	err := c.MetaRouterControllerClient.List(context.TODO(), &metaRouterList,
+		client.MatchingFields{constants.HostIndex: service.Hosts[0]})
 	if err != nil {
 		return nil, err
 	}
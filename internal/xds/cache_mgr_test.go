@@ -0,0 +1,186 @@
+// Copyright 2020 Envoyproxy Authors
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package xds
+
+import (
+	"testing"
+
+	metaprotocolapi "github.com/aeraki-mesh/api/metaprotocol/v1alpha1"
+	metaprotocol "github.com/aeraki-mesh/client-go/pkg/apis/metaprotocol/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	networking "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/aeraki-mesh/aeraki/internal/model"
+)
+
+const metaHost = "thrift-sample-server.meta-thrift.svc.cluster.local"
+
+func hashTrafficPolicy(header string) *networking.TrafficPolicy {
+	return &networking.TrafficPolicy{
+		LoadBalancer: &networking.LoadBalancerSettings{
+			LbPolicy: &networking.LoadBalancerSettings_ConsistentHash{
+				ConsistentHash: &networking.LoadBalancerSettings_ConsistentHashLB{
+					HashKey: &networking.LoadBalancerSettings_ConsistentHashLB_HttpHeaderName{
+						HttpHeaderName: header,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestConstructActionHashPolicy(t *testing.T) {
+	port := &networking.ServicePort{Number: 9090, Name: "tcp-metaprotocol-thrift"}
+	dr := &model.DestinationRuleWrapper{Spec: &networking.DestinationRule{
+		Host:          metaHost,
+		TrafficPolicy: hashTrafficPolicy("x-tenant"),
+		Subsets: []*networking.Subset{
+			{Name: "v1", TrafficPolicy: hashTrafficPolicy("x-user")},
+			{Name: "v2", TrafficPolicy: hashTrafficPolicy("x-user")},
+			{Name: "v3"},
+		},
+	}}
+	destination := func(subset string, weight uint32) *metaprotocolapi.MetaRouteDestination {
+		return &metaprotocolapi.MetaRouteDestination{
+			Destination: &metaprotocolapi.Destination{Host: metaHost, Subset: subset},
+			Weight:      weight,
+		}
+	}
+
+	cases := []struct {
+		name  string
+		route *metaprotocolapi.MetaRoute
+		want  []string
+	}{
+		{
+			name:  "service",
+			route: &metaprotocolapi.MetaRoute{Route: []*metaprotocolapi.MetaRouteDestination{destination("", 0)}},
+			want:  []string{"x-tenant"},
+		},
+		{
+			name:  "subset",
+			route: &metaprotocolapi.MetaRoute{Route: []*metaprotocolapi.MetaRouteDestination{destination("v1", 0)}},
+			want:  []string{"x-user"},
+		},
+		{
+			// a subset doesn't inherit the load balancer settings of the service
+			name:  "subset without load balancer",
+			route: &metaprotocolapi.MetaRoute{Route: []*metaprotocolapi.MetaRouteDestination{destination("v3", 0)}},
+		},
+		{
+			name: "weighted subsets",
+			route: &metaprotocolapi.MetaRoute{Route: []*metaprotocolapi.MetaRouteDestination{
+				destination("v1", 50), destination("v3", 50),
+			}},
+			want: []string{"x-user"},
+		},
+	}
+
+	c := &CacheMgr{}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := c.constructAction(port, tc.route, dr).HashPolicy
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected hash policy (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConstructRouteDynamicSubsets(t *testing.T) {
+	service := &networking.ServiceEntry{
+		Hosts: []string{metaHost},
+		Ports: []*networking.ServicePort{{Number: 9090, Name: "tcp-metaprotocol-thrift"}},
+	}
+	dr := &model.DestinationRuleWrapper{Spec: &networking.DestinationRule{
+		Host:    metaHost,
+		Subsets: []*networking.Subset{{Name: "v1"}, {Name: "cell-a"}, {Name: "cell-b"}},
+	}}
+	methodRoute := &metaprotocolapi.MetaRoute{
+		Name: "method",
+		Match: &metaprotocolapi.MetaRouteMatch{Attributes: map[string]*metaprotocolapi.StringMatch{
+			"method": {MatchType: &metaprotocolapi.StringMatch_Exact{Exact: "sayHello"}},
+		}},
+		Route: []*metaprotocolapi.MetaRouteDestination{
+			{Destination: &metaprotocolapi.Destination{Host: metaHost, Subset: "v1"}},
+		},
+	}
+	fallbackRoute := &metaprotocolapi.MetaRoute{
+		Name: "fallback",
+		Route: []*metaprotocolapi.MetaRouteDestination{
+			{Destination: &metaprotocolapi.Destination{Host: metaHost}},
+		},
+		Mirror:          &metaprotocolapi.Destination{Host: metaHost, Subset: "v1"},
+		RequestMutation: []*metaprotocolapi.KeyValue{{Key: "foo", Value: "bar"}},
+	}
+
+	cases := []struct {
+		name       string
+		routes     []*metaprotocolapi.MetaRoute
+		wantRoutes []string
+	}{
+		{
+			name:       "before the fallback route",
+			routes:     []*metaprotocolapi.MetaRoute{methodRoute, fallbackRoute},
+			wantRoutes: []string{"method", "dynamic-cell-a", "dynamic-cell-b", "fallback"},
+		},
+		{
+			name:       "no fallback route",
+			routes:     []*metaprotocolapi.MetaRoute{methodRoute},
+			wantRoutes: []string{"method", "dynamic-cell-a", "dynamic-cell-b"},
+		},
+	}
+
+	c := &CacheMgr{}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			metaRouter := &metaprotocol.MetaRouter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					Annotations: map[string]string{dynamicSubsetAnnotation: "x-cell=cell-"},
+				},
+				Spec: metaprotocolapi.MetaRouter{Hosts: []string{metaHost}, Routes: tc.routes},
+			}
+			routes := c.constructRoute(service, service.Ports[0], metaRouter, dr).Routes
+			var gotRoutes []string
+			for _, route := range routes {
+				gotRoutes = append(gotRoutes, route.Name)
+			}
+			if diff := cmp.Diff(tc.wantRoutes, gotRoutes); diff != "" {
+				t.Fatalf("unexpected routes (-want +got):\n%s", diff)
+			}
+
+			dynamic := routes[1]
+			if got := dynamic.Match.Metadata[0]; got.Name != "x-cell" || got.GetExactMatch() != "a" {
+				t.Errorf("unexpected match of dynamic route: %v", got)
+			}
+			if got := dynamic.Route.GetCluster(); got != "outbound|9090|cell-a|"+metaHost {
+				t.Errorf("unexpected cluster of dynamic route: %s", got)
+			}
+			wantMirrors := 0
+			if len(tc.routes) > 1 {
+				wantMirrors = 1
+			}
+			if len(dynamic.Route.RequestMirrorPolicies) != wantMirrors || len(dynamic.RequestMutation) != wantMirrors {
+				t.Errorf("dynamic route should keep the mirrors and mutations of the fallback route: %v", dynamic)
+			}
+			// the fallback route must not be modified
+			if got := routes[len(routes)-1].Route.GetCluster(); len(tc.routes) > 1 && got != "outbound|9090||"+metaHost {
+				t.Errorf("unexpected cluster of fallback route: %s", got)
+			}
+		})
+	}
+}